@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
 )
 
-func categoryCmd(categories []Category) *cobra.Command {
+func categoryCmd(categories []api.Category) *cobra.Command {
 	return &cobra.Command{
 		Use:   "category",
 		Short: "List supported extension categories, use the normalized name in extension.yaml.",
@@ -10,6 +10,7 @@ import (
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 
+	"github.com/kubesphere/ksbuilder/pkg/api"
 	"github.com/kubesphere/ksbuilder/pkg/extension"
 )
 
@@ -29,59 +30,7 @@ type createOptions struct {
 	from string
 }
 
-type Category struct {
-	DisplayNameEN  string
-	NormalizedName string
-}
-
-var Categories = []Category{
-	{
-		DisplayNameEN:  "AI / LLM",
-		NormalizedName: "ai-machine-learning",
-	},
-	{
-		DisplayNameEN:  "DeepSeek",
-		NormalizedName: "deepseek",
-	},
-	{
-		DisplayNameEN:  "Database",
-		NormalizedName: "database",
-	},
-	{
-		DisplayNameEN:  "Observability",
-		NormalizedName: "observability",
-	},
-	{
-		DisplayNameEN:  "CI / CD",
-		NormalizedName: "integration-delivery",
-	},
-	{
-		DisplayNameEN:  "Networking",
-		NormalizedName: "networking",
-	},
-	{
-		DisplayNameEN:  "Security",
-		NormalizedName: "security",
-	},
-	{
-		DisplayNameEN:  "Storage",
-		NormalizedName: "storage",
-	},
-	{
-		DisplayNameEN:  "Streaming and messaging",
-		NormalizedName: "streaming-messaging",
-	},
-	{
-		DisplayNameEN:  "Computing",
-		NormalizedName: "computing",
-	},
-	{
-		DisplayNameEN:  "DevTools",
-		NormalizedName: "dev-tools",
-	},
-}
-
-func getCategoryDisplayNames(categories []Category) []string {
+func getCategoryDisplayNames(categories []api.Category) []string {
 	var names []string
 	for _, c := range categories {
 		names = append(names, c.DisplayNameEN)
@@ -110,7 +59,7 @@ func (o *createOptions) run(_ *cobra.Command, _ []string) error {
 	}
 	name := promptGetInput(extensionNamePrompt)
 
-	categoryDisplayNames := getCategoryDisplayNames(Categories)
+	categoryDisplayNames := getCategoryDisplayNames(api.Categories)
 	categoryPromptContent := selectPromptContent{
 		text:  fmt.Sprintf("What category does %s belong to?", name),
 		items: categoryDisplayNames,
@@ -137,7 +86,7 @@ func (o *createOptions) run(_ *cobra.Command, _ []string) error {
 
 	extensionConfig := extension.Config{
 		Name:     name,
-		Category: Categories[categoryIdx].NormalizedName,
+		Category: api.Categories[categoryIdx].NormalizedName,
 		Author:   author,
 		Email:    email,
 		URL:      url,
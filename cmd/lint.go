@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 
 	"github.com/kubesphere/ksbuilder/cmd/options"
+	"github.com/kubesphere/ksbuilder/pkg/api"
 	"github.com/kubesphere/ksbuilder/pkg/extension"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
 )
 
 func lintExtensionCmd() *cobra.Command {
@@ -19,21 +24,78 @@ func lintExtensionCmd() *cobra.Command {
 			"the chart is well-formed.",
 		Long: "If the linter encounters things that will cause the chart to fail installation,\n" +
 			"it will emit [ERROR] messages. If it encounters issues that break with convention\n" +
-			"or recommendation, it will emit [WARNING] messages.",
+			"or recommendation, it will emit [WARNING] messages.\n\n" +
+			"The command exits with a non-zero status when any [ERROR] is found by helm or\n" +
+			"the builtin KubeSphere rules, or any [WARNING] when --strict is set.\n\n" +
+			"The findings on a rendered resource are suppressed by listing their rule IDs, or helm\n" +
+			"for the findings of helm on its template, in the annotation or a comment of the resource:\n\n" +
+			"  lint.ksbuilder.kubesphere.io/ignore: global-tolerations,helm\n" +
+			"  # lint.ksbuilder.kubesphere.io/ignore: global-tolerations,helm\n\n" +
+			"Suppressed findings don't fail the lint run, and are listed in the report for review.\n\n" +
+			"To adopt lint in an extension with legacy findings, record them by --write-baseline,\n" +
+			"then --baseline reports only the new findings. The findings are identified by their\n" +
+			"rule, severity, file and resource, and by their messages when they have no resource or\n" +
+			"are of helm, so that they are still known when the extension is edited.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			paths := []string{"."}
 			if len(args) > 0 {
 				paths = args
 			}
 
-			// when helm lint is error. continue run builtins lint
-			_ = extension.WithHelm(o, paths)
+			if err := lint.ValidateOutputFormat(o.Output); err != nil {
+				return err
+			}
+
+			trees, err := extension.LoadTrees(paths, o.Client.WithSubcharts)
+			if err != nil {
+				return err
+			}
+			defer extension.CloseTrees(trees)
+			for _, t := range trees {
+				if !t.IsExtension() {
+					fmt.Fprintf(os.Stderr, "%s is a helm chart without %s, only the helm linter runs against it\n", t.Path(), api.MetadataFilename)
+				}
+			}
 
-			if err := extension.WithBuiltins(o, paths); err != nil {
+			result := &lint.Result{Strict: o.Client.Strict}
+			// when helm lint reports errors, continue to run builtins lint
+			// so that the report covers both phases.
+			if err := extension.WithHelm(o, trees, result); err != nil {
+				return err
+			}
+			if err := extension.WithBuiltins(o, trees, result); err != nil {
 				return err
 			}
 
-			return nil
+			// the baseline is written before applying --baseline, so that it keeps the known findings
+			var written *lint.Baseline
+			if o.WriteBaseline != "" {
+				written = lint.NewBaseline(result.Findings, paths)
+				if err := written.Write(o.WriteBaseline); err != nil {
+					return err
+				}
+			}
+			if o.Baseline != "" {
+				baseline, err := lint.LoadBaseline(o.Baseline)
+				if err != nil {
+					return err
+				}
+				result.ApplyBaseline(baseline, paths)
+			}
+
+			// the baseline records the info findings as well, so that it is not rewritten without --quiet
+			if o.Client.Quiet {
+				result.DropInfos()
+			}
+			if err := lint.Print(os.Stdout, o.Output, result); err != nil {
+				return err
+			}
+			// the recorded findings are known from now on, so writing the baseline doesn't fail the lint run
+			if written != nil {
+				fmt.Fprintf(os.Stderr, "%d finding(s) recorded to the baseline %s\n", len(written.Findings), o.WriteBaseline)
+				return nil
+			}
+			return result.Err()
 		},
 	}
 
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+)
+
+type migrateOptions struct {
+	set map[string]string
+}
+
+func migrateExtensionCmd() *cobra.Command {
+	o := &migrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate <extension>...",
+		Short: "Migrate extension.yaml to the current version of its schema",
+		Long: fmt.Sprintf(`Migrate extension.yaml of the extensions to %s, the current version of its schema,
+such as the files written before the schema was versioned, which have no apiVersion.
+
+The fields are edited in place, keeping the comments and the indentation. The values of the required fields
+the file lacks are asked for by prompt, unless they are given by --set, e.g. --set category=database --set provider.en.name=KubeSphere.
+The file is only written when the migrated one is valid.`, api.APIVersion),
+		Args: cobra.MinimumNArgs(1),
+		RunE: o.migrate,
+	}
+	cmd.Flags().StringToStringVar(&o.set, "set", nil, "the values of the required fields to fill instead of prompting, by their paths")
+	return cmd
+}
+
+func (o *migrateOptions) migrate(_ *cobra.Command, args []string) error {
+	pwd, _ := os.Getwd()
+	for _, arg := range args {
+		p := arg
+		if !path.IsAbs(p) {
+			p = path.Join(pwd, p)
+		}
+		if err := o.migrateExtension(p); err != nil {
+			return fmt.Errorf("unable to migrate %s: %w", arg, err)
+		}
+	}
+	return nil
+}
+
+func (o *migrateOptions) migrateExtension(p string) error {
+	file := path.Join(p, api.MetadataFilename)
+	fi, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	migrated, from, err := api.Migrate(data, o.prompt)
+	if err != nil {
+		return err
+	}
+	if from == api.APIVersion {
+		fmt.Printf("%s is already at %s\n", file, api.APIVersion)
+		return nil
+	}
+	// the file is left as it is unless the migrated one is valid, the problems the migration can't fix are fixed by hand
+	if _, err = api.ParseMetadata(migrated); err != nil {
+		return fmt.Errorf("%s is not migrated, fix the problems and run it again: %w", api.MetadataFilename, err)
+	}
+	if err = writeFileAtomically(file, migrated, fi.Mode()); err != nil {
+		return err
+	}
+	if from == "" {
+		from = "no apiVersion"
+	}
+	fmt.Printf("%s is migrated from %s to %s\n", file, from, api.APIVersion)
+	return nil
+}
+
+// writeFileAtomically replaces the file with the data by renaming a temporary file next to it,
+// so that the file is never left half written.
+func writeFileAtomically(file string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(path.Dir(file), "."+path.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // nolint
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+func (o *migrateOptions) prompt(path, description string) (string, error) {
+	if value, ok := o.set[path]; ok {
+		return value, nil
+	}
+	if path == "category" {
+		idx := promptGetSelect(selectPromptContent{
+			text:  "What category does the extension belong to?",
+			items: getCategoryDisplayNames(api.Categories),
+		})
+		return api.Categories[idx].NormalizedName, nil
+	}
+	return promptGetInput(inputPromptContent{
+		text:     fmt.Sprintf("Please input %s (%s)", path, strings.TrimSuffix(description, ".")),
+		errorMsg: fmt.Sprintf("%s can't be empty", path),
+	}), nil
+}
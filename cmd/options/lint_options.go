@@ -1,21 +1,51 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/cli/values"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
 )
 
 type LintOptions struct {
-	Client    *action.Lint
-	ValueOpts *values.Options
-	Settings  *cli.EnvSettings
+	// Output is the format of the lint report, one of text, json, sarif or junit.
+	Output string
+	// EnableRules and DisableRules turn builtin lint rules on or off, they override the lint configuration file.
+	EnableRules  []string
+	DisableRules []string
+	// Catalog is a directory of the extension.yaml files of other extensions, to resolve external dependencies.
+	Catalog string
+	// TargetKSVersion and TargetKubeVersion are the versions the extension is checked against,
+	// the chart is rendered with the capabilities of TargetKubeVersion.
+	TargetKSVersion   string
+	TargetKubeVersion string
+	// ValuesMatrix is a file of values combinations, the builtin rules lint the chart rendered with each of them.
+	// The helm linter lints the chart with the --values and --set values only.
+	ValuesMatrix string
+	// Schemas is a directory of CustomResourceDefinitions, the rendered custom resources are validated against them
+	// in addition to the bundled schemas and the CRDs of the chart.
+	Schemas string
+	// Security enables the opt-in rules checking the security posture of the rendered workloads.
+	Security bool
+	// WriteBaseline is the file to record the current findings to, and Baseline is the file of the known findings
+	// that are not reported, see lint.Baseline.
+	WriteBaseline string
+	Baseline      string
+	Client        *action.Lint
+	ValueOpts     *values.Options
+	Settings      *cli.EnvSettings
 }
 
 func NewLintOptions() *LintOptions {
-	o := &LintOptions{}
+	o := &LintOptions{
+		Output: lint.OutputText,
+	}
 	o.Client = action.NewLint()
 	o.ValueOpts = new(values.Options)
 	o.Settings = cli.New()
@@ -23,10 +53,22 @@ func NewLintOptions() *LintOptions {
 }
 
 func (o *LintOptions) AddFlags(cmd *cobra.Command, f *pflag.FlagSet) {
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, fmt.Sprintf("the format of the lint report, one of %s", strings.Join(lint.OutputFormats, ", ")))
+	cmd.Flags().StringSliceVar(&o.EnableRules, "enable", []string{}, fmt.Sprintf("enable builtin lint rules by ID, overriding %s (can specify multiple)", lint.ConfigFilename))
+	cmd.Flags().StringSliceVar(&o.DisableRules, "disable", []string{}, fmt.Sprintf("disable builtin lint rules by ID, overriding %s (can specify multiple)", lint.ConfigFilename))
+	cmd.Flags().StringVar(&o.Catalog, "catalog", "", "a directory of the extension.yaml files of other extensions, to resolve external dependencies against")
+	cmd.Flags().StringVar(&o.TargetKSVersion, "target-ks-version", "", "check ksVersion against the KubeSphere version, e.g. 4.1.0")
+	cmd.Flags().StringVar(&o.TargetKubeVersion, "target-kube-version", "", "render the chart for the Kubernetes version, and check kubeVersion and deprecated APIs against it, e.g. 1.30.0")
+	cmd.Flags().StringVar(&o.ValuesMatrix, "values-matrix", "", "a YAML file of values, the builtin rules lint the chart rendered with every combination of them, the helm linter doesn't use it")
+	cmd.Flags().StringVar(&o.Schemas, "schemas", "", "a directory of CustomResourceDefinitions to validate the rendered custom resources against, in addition to the bundled schemas and the CRDs of the chart")
+	cmd.Flags().StringVar(&o.WriteBaseline, "write-baseline", "", fmt.Sprintf("record the current findings to the baseline file, the lint run doesn't fail when it writes the baseline, e.g. %s", lint.DefaultBaselineFilename))
+	cmd.Flags().StringVar(&o.Baseline, "baseline", "", "report only the findings that are not in the baseline file written by --write-baseline")
+	cmd.Flags().BoolVar(&o.Security, "security", false, "enable the security rule pack, checking privileges, host access, resources, image tags, users and probes of the rendered workloads, except the rules disabled by the configuration file or --disable")
+
 	// client flags
-	cmd.Flags().BoolVar(&o.Client.Strict, "strict", false, "fail on lint warnings")
+	cmd.Flags().BoolVar(&o.Client.Strict, "strict", false, "fail on lint warnings, both of helm and the builtin KubeSphere rules")
 	cmd.Flags().BoolVar(&o.Client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
-	cmd.Flags().BoolVar(&o.Client.Quiet, "quiet", false, "print only warnings and errors")
+	cmd.Flags().BoolVar(&o.Client.Quiet, "quiet", false, "print only warnings and errors, of both helm and the builtin KubeSphere rules")
 
 	// value flags
 	cmd.Flags().StringSliceVarP(&o.ValueOpts.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
@@ -77,13 +77,22 @@ func (o *publishOptions) publish(_ *cobra.Command, args []string) error {
 			}
 		}
 
+		written := make(map[string]bool)
 		for _, obj := range ext.ToKubernetesResources() {
-			fmt.Printf("creating %s %s\n", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			fmt.Printf("creating %s %s\n", kind, obj.GetName())
 			data, err := yaml.Marshal(obj)
 			if err != nil {
 				return err
 			}
-			if err := os.WriteFile(filepath.Join(o.output, obj.GetObjectKind().GroupVersionKind().Kind+".yaml"), data, 0644); err != nil {
+			// the first resource of a kind is written to <kind>.yaml, and the others, such as the ConfigMap
+			// of the README and CHANGELOG files after the one of the chart, are named after themselves
+			filename := kind + ".yaml"
+			if written[kind] {
+				filename = fmt.Sprintf("%s-%s.yaml", kind, obj.GetName())
+			}
+			written[kind] = true
+			if err := os.WriteFile(filepath.Join(o.output, filename), data, 0644); err != nil {
 				return err
 			}
 		}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
 )
 
 func NewRootCmd(version string) *cobra.Command {
@@ -19,7 +21,7 @@ func NewRootCmd(version string) *cobra.Command {
 	}
 
 	cmd.AddCommand(versionCmd(version))
-	cmd.AddCommand(categoryCmd(Categories))
+	cmd.AddCommand(categoryCmd(api.Categories))
 	cmd.AddCommand(createExtensionCmd())
 	cmd.AddCommand(createSimpleExtensionCmd())
 	cmd.AddCommand(createAppExtensionCmd())
@@ -27,6 +29,7 @@ func NewRootCmd(version string) *cobra.Command {
 	cmd.AddCommand(packageExtensionCmd())
 	cmd.AddCommand(unpublishExtensionCmd())
 	cmd.AddCommand(validateExtensionCmd())
+	cmd.AddCommand(migrateExtensionCmd())
 	cmd.AddCommand(lintExtensionCmd())
 	cmd.AddCommand(templateExtensionCmd())
 	cmd.AddCommand(loginCmd())
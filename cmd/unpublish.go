@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +13,7 @@ import (
 	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kubesphere/ksbuilder/pkg/api"
 	"github.com/kubesphere/ksbuilder/pkg/utils"
 )
 
@@ -67,7 +69,20 @@ func (o *unpublishOptions) unpublish(_ *cobra.Command, args []string) error {
 				Name:      fmt.Sprintf("extension-%s-chart", version.Name),
 				Namespace: "kubesphere-system",
 			},
-		}, version)
+		})
+		if namespace, name, ok := strings.Cut(version.Annotations[api.DocsRefAnnotation], "/"); ok {
+			objs = append(objs, &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			})
+		}
+		objs = append(objs, version)
 	}
 
 	return deleteObjs(genericClient, append(objs, &corev1alpha1.InstallPlan{
@@ -0,0 +1,64 @@
+package api
+
+// Category is a category of extensions in the marketplace, extension.yaml uses its normalized name.
+type Category struct {
+	DisplayNameEN  string
+	NormalizedName string
+}
+
+// Categories are the categories of the marketplace.
+var Categories = []Category{
+	{
+		DisplayNameEN:  "AI / LLM",
+		NormalizedName: "ai-machine-learning",
+	},
+	{
+		DisplayNameEN:  "DeepSeek",
+		NormalizedName: "deepseek",
+	},
+	{
+		DisplayNameEN:  "Database",
+		NormalizedName: "database",
+	},
+	{
+		DisplayNameEN:  "Observability",
+		NormalizedName: "observability",
+	},
+	{
+		DisplayNameEN:  "CI / CD",
+		NormalizedName: "integration-delivery",
+	},
+	{
+		DisplayNameEN:  "Networking",
+		NormalizedName: "networking",
+	},
+	{
+		DisplayNameEN:  "Security",
+		NormalizedName: "security",
+	},
+	{
+		DisplayNameEN:  "Storage",
+		NormalizedName: "storage",
+	},
+	{
+		DisplayNameEN:  "Streaming and messaging",
+		NormalizedName: "streaming-messaging",
+	},
+	{
+		DisplayNameEN:  "Computing",
+		NormalizedName: "computing",
+	},
+	{
+		DisplayNameEN:  "DevTools",
+		NormalizedName: "dev-tools",
+	},
+}
+
+// categoryNames returns the normalized names of Categories, which the category of extension.yaml must be one of.
+func categoryNames() []string {
+	names := make([]string, 0, len(Categories))
+	for _, c := range Categories {
+		names = append(names, c.NormalizedName)
+	}
+	return names
+}
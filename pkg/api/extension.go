@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"maps"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,6 +14,9 @@ const (
 	KubeSphereSystem  = "kubesphere-system"
 	ConfigMapDataKey  = "chart.tgz"
 	KubeSphereManaged = "kubesphere.io/managed"
+	// DocsRefAnnotation refers to the ConfigMap of the localized README and CHANGELOG of an ExtensionVersion,
+	// as <namespace>/<name>, which are keyed by their filenames, e.g. README_zh.md
+	DocsRefAnnotation = "kubesphere.io/docs-ref"
 )
 
 type Extension struct {
@@ -21,6 +25,10 @@ type Extension struct {
 	ChartURL string
 	// ChartData valid when the chart source local.
 	ChartData []byte
+	// README and Changelog are the localized README and CHANGELOG files, published with the ExtensionVersion
+	// so that the marketplace shows them.
+	README    corev1alpha1.Locales
+	Changelog corev1alpha1.Locales
 }
 
 func (ext *Extension) ToKubernetesResources() []runtimeclient.Object {
@@ -113,5 +121,45 @@ func (ext *Extension) ToKubernetesResources() []runtimeclient.Object {
 		}
 		resources = append(resources, extensionVersion, configmap)
 	}
+	if docs := ext.docsConfigMap(); docs != nil {
+		extensionVersion.Annotations = maps.Clone(extensionVersion.Annotations)
+		if extensionVersion.Annotations == nil {
+			extensionVersion.Annotations = make(map[string]string)
+		}
+		extensionVersion.Annotations[DocsRefAnnotation] = fmt.Sprintf("%s/%s", docs.Namespace, docs.Name)
+		resources = append(resources, docs)
+	}
 	return resources
 }
+
+// docsConfigMap returns the ConfigMap of the localized README and CHANGELOG files, or nil when there are none.
+func (ext *Extension) docsConfigMap() *corev1.ConfigMap {
+	data := make(map[string]string)
+	for lang, content := range ext.README {
+		if content != "" {
+			data[ReadmeFilename(lang)] = string(content)
+		}
+	}
+	for lang, content := range ext.Changelog {
+		if content != "" {
+			data[ChangelogFilename(lang)] = string(content)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("extension-%s-%s-docs", ext.Metadata.Name, ext.Metadata.Version),
+			Namespace: KubeSphereSystem,
+			Labels: map[string]string{
+				corev1alpha1.ExtensionReferenceLabel: ext.Metadata.Name,
+			},
+		},
+		Data: data,
+	}
+}
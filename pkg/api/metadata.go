@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"mime"
 	"net/http"
@@ -9,13 +10,10 @@ import (
 	ospath "path"
 	"strings"
 
-	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/chart"
 	"k8s.io/apimachinery/pkg/util/json"
 	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
-	"sigs.k8s.io/yaml"
-
-	"github.com/kubesphere/ksbuilder/pkg/iso639"
 )
 
 const MetadataFilename = "extension.yaml"
@@ -24,27 +22,30 @@ type Metadata struct {
 	APIVersion string `json:"apiVersion" validate:"required"`
 	// The name of the chart. Required.
 	Name                 string                                               `json:"name" validate:"required"`
-	Version              string                                               `json:"version" validate:"required"`
+	Version              string                                               `json:"version" validate:"required,semver"`
 	DisplayName          corev1alpha1.Locales                                 `json:"displayName" validate:"required"`
 	Description          corev1alpha1.Locales                                 `json:"description" validate:"required"`
-	Category             string                                               `json:"category" validate:"required"`
+	Category             string                                               `json:"category" validate:"required,category"`
 	Keywords             []string                                             `json:"keywords,omitempty"`
-	Home                 string                                               `json:"home,omitempty"`
-	Docs                 string                                               `json:"docs,omitempty"`
-	Sources              []string                                             `json:"sources,omitempty"`
+	Home                 string                                               `json:"home,omitempty" validate:"omitempty,http_url"`
+	Docs                 string                                               `json:"docs,omitempty" validate:"omitempty,http_url"`
+	Sources              []string                                             `json:"sources,omitempty" validate:"dive,http_url"`
 	KubeVersion          string                                               `json:"kubeVersion,omitempty"`
 	KSVersion            string                                               `json:"ksVersion,omitempty"`
-	Maintainers          []*chart.Maintainer                                  `json:"maintainers,omitempty"`
+	Maintainers          []*chart.Maintainer                                  `json:"maintainers,omitempty" validate:"dive"`
 	Provider             map[corev1alpha1.LanguageCode]*corev1alpha1.Provider `json:"provider" validate:"required"`
 	StaticFileDirectory  string                                               `json:"staticFileDirectory,omitempty"`
 	Icon                 string                                               `json:"icon" validate:"required"`
 	Screenshots          []string                                             `json:"screenshots,omitempty"`
 	Dependencies         []*chart.Dependency                                  `json:"dependencies,omitempty"`
-	InstallationMode     corev1alpha1.InstallationMode                        `json:"installationMode,omitempty"`
-	Namespace            string                                               `json:"namespace,omitempty"`
+	InstallationMode     corev1alpha1.InstallationMode                        `json:"installationMode,omitempty" validate:"omitempty,oneof=HostOnly Multicluster"`
+	Namespace            string                                               `json:"namespace,omitempty" validate:"omitempty,dns1123_label"`
 	Images               []string                                             `json:"images,omitempty"`
 	ExternalDependencies []corev1alpha1.ExternalDependency                    `json:"externalDependencies,omitempty"`
-	Annotations          map[string]string                                    `json:"annotations,omitempty"`
+	Annotations          map[string]string                                    `json:"annotations,omitempty" validate:"dive,keys,annotation_key,endkeys"`
+
+	// document is the YAML node of extension.yaml the metadata is parsed from, which locates the problems of the fields
+	document *yaml.Node
 }
 
 type Options struct {
@@ -81,57 +82,50 @@ func LoadMetadata(path string, options ...func(*Options)) (*Metadata, error) {
 		}
 		metadata.Icon = base64EncodedIcon
 	}
-
-	if err = metadata.Validate(); err != nil {
-		return nil, err
-	}
 	return metadata, nil
 }
 
+// ParseMetadata decodes and validates extension.yaml. It checks the file against MetadataSchema and by Validate
+// in one go, and returns FieldErrors of all the fields that are unknown or invalid in either way.
 func ParseMetadata(data []byte) (*Metadata, error) {
-	metadata := new(Metadata)
-	if err := yaml.Unmarshal(data, metadata); err != nil {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	result, err := validateMetadataSchema(&document, data)
+	if err != nil {
 		return nil, err
 	}
+	metadata, err := decodeMetadata(data)
+	switch {
+	case err != nil && len(result) == 0:
+		return nil, err
+	case err != nil && metadata == nil:
+		// the fields can't be validated without decoding them, but the problems of MetadataSchema are still reported
+		sortFieldErrors(result)
+		return nil, append(result, &FieldError{Message: err.Error()})
+	}
+	// the values of the wrong types are reported against MetadataSchema, the other fields are still validated
+
+	metadata.document = document.Content[0]
 
 	// set default value for necessary fields
 	if metadata.InstallationMode == "" {
 		metadata.InstallationMode = corev1alpha1.InstallationModeHostOnly
 	}
-	return metadata, nil
-}
-
-func validateLanguageCode(code corev1alpha1.LanguageCode) error {
-	if !iso639.IsValidLanguageCode(code) {
-		return fmt.Errorf("invalid language code %s, see https://www.loc.gov/standards/iso639-2/php/code_list.php for more details", code)
-	}
-	return nil
-}
-
-func (md *Metadata) validateLanguageCode() error {
-	for code := range md.DisplayName {
-		if err := validateLanguageCode(code); err != nil {
-			return err
-		}
-	}
-	for code := range md.Description {
-		if err := validateLanguageCode(code); err != nil {
-			return err
-		}
-	}
-	for code := range md.Provider {
-		if err := validateLanguageCode(code); err != nil {
-			return err
+	if err = metadata.Validate(); err != nil {
+		var fieldErrors FieldErrors
+		if !errors.As(err, &fieldErrors) {
+			return nil, err
 		}
+		result = mergeFieldErrors(result, fieldErrors)
+	} else {
+		sortFieldErrors(result)
 	}
-	return nil
-}
-
-func (md *Metadata) Validate() error {
-	if err := validator.New().Struct(md); err != nil {
-		return err
+	if len(result) > 0 {
+		return nil, result
 	}
-	return md.validateLanguageCode()
+	return metadata, nil
 }
 
 func (md *Metadata) ToChartYaml() *chart.Metadata {
@@ -159,6 +153,23 @@ func DeepCopy(md *chart.Metadata) *chart.Metadata {
 	return out
 }
 
+// ReadmeFilename returns the name of the README file in the language, the English one is README.md.
+func ReadmeFilename(lang corev1alpha1.LanguageCode) string {
+	return localizedFilename("README", lang)
+}
+
+// ChangelogFilename returns the name of the CHANGELOG file in the language, the English one is CHANGELOG.md.
+func ChangelogFilename(lang corev1alpha1.LanguageCode) string {
+	return localizedFilename("CHANGELOG", lang)
+}
+
+func localizedFilename(name string, lang corev1alpha1.LanguageCode) string {
+	if lang == corev1alpha1.LanguageCodeEn {
+		return name + ".md"
+	}
+	return fmt.Sprintf("%s_%s.md", name, lang)
+}
+
 func IsLocalFile(path string) bool {
 	if strings.HasPrefix(path, "http://") ||
 		strings.HasPrefix(path, "https://") ||
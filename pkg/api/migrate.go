@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
+)
+
+// Prompt asks for the value of the field at the path of extension.yaml, e.g. category or displayName.en,
+// with the description of the field in MetadataSchema.
+type Prompt func(path, description string) (string, error)
+
+// migration upgrades extension.yaml from a version of the schema to the next one.
+type migration struct {
+	from, to string
+	// migrate rewrites the fields of extension.yaml in place
+	migrate func(root *yaml.Node) error
+}
+
+// migrations are the upgrades between the versions of the schema, each one picks up where its predecessor leaves off.
+// The files written before the schema was versioned have no apiVersion, which is set by the first one.
+var migrations = []migration{
+	{from: "", to: APIVersion, migrate: func(root *yaml.Node) error { return nil }},
+}
+
+// migrationFrom returns the migration from the version of the schema.
+func migrationFrom(version string) (migration, bool) {
+	for _, m := range migrations {
+		if m.from == version {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+// Migrate upgrades extension.yaml to APIVersion step by step, then asks for the values of the required fields
+// it lacks by prompt, such as the ones added by the newer versions. The fields are edited in place, so the comments,
+// the order of the fields and the indentation of the file are kept.
+// It returns the upgraded file and the version it was written in, the file is returned as it is when it is at APIVersion.
+func Migrate(data []byte, prompt Prompt) ([]byte, string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, "", err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("%s must be a mapping of the fields", MetadataFilename)
+	}
+	root := document.Content[0]
+	_, versionNode := mappingEntry(root, "apiVersion")
+	if versionNode == nil {
+		// apiVersion goes first like in the files ksbuilder creates
+		versionNode = stringNode("")
+		root.Content = append([]*yaml.Node{stringNode("apiVersion"), versionNode}, root.Content...)
+	}
+	from := versionNode.Value
+	if from == APIVersion {
+		return data, from, nil
+	}
+
+	for versionNode.Value != APIVersion {
+		m, ok := migrationFrom(versionNode.Value)
+		if !ok {
+			return nil, "", fmt.Errorf("unable to migrate %s from %q, it is not a version of the schema ksbuilder knows", MetadataFilename, versionNode.Value)
+		}
+		if err := m.migrate(root); err != nil {
+			return nil, "", fmt.Errorf("unable to migrate %s from %q to %s: %w", MetadataFilename, m.from, m.to, err)
+		}
+		versionNode.Value = m.to
+	}
+	if err := fillRequiredFields(root, prompt); err != nil {
+		return nil, "", err
+	}
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	indent := indentation(root)
+	if indent == 0 {
+		indent = 2
+	}
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, "", err
+	}
+	return b.Bytes(), from, nil
+}
+
+// indentation returns the number of spaces the first nested block mapping of the node is indented by,
+// 0 when there is none.
+func indentation(n *yaml.Node) int {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if value.Kind == yaml.MappingNode && value.Style&yaml.FlowStyle == 0 && value.Line > key.Line && value.Column > key.Column {
+				return value.Column - key.Column
+			}
+		}
+	}
+	for _, c := range n.Content {
+		if indent := indentation(c); indent > 0 {
+			return indent
+		}
+	}
+	return 0
+}
+
+// fillRequiredFields asks for the values of the required fields of MetadataSchema that extension.yaml lacks,
+// the localized ones are filled in the default language. The fields of other types are left to the validation.
+func fillRequiredFields(root *yaml.Node, prompt Prompt) error {
+	schema := metadataSchema()
+	lang := string(corev1alpha1.DefaultLanguageCode)
+	for _, field := range schema.Required {
+		if _, n := mappingEntry(root, field); n != nil && (n.Kind != yaml.ScalarNode || n.Value != "") {
+			continue
+		}
+
+		property := schema.Properties[field]
+		var path string
+		var wrap func(*yaml.Node) *yaml.Node
+		switch {
+		case property.Type.Contains("string"):
+			path, wrap = field, func(n *yaml.Node) *yaml.Node { return n }
+		case property.AdditionalProperties == nil || property.AdditionalProperties.Schema == nil:
+			continue
+		case property.AdditionalProperties.Schema.Type.Contains("string"):
+			path, wrap = fmt.Sprintf("%s.%s", field, lang), localized
+		case property.AdditionalProperties.Schema.Properties["name"].Type.Contains("string"):
+			path, wrap = fmt.Sprintf("%s.%s.name", field, lang), func(n *yaml.Node) *yaml.Node {
+				return localized(mappingNode(stringNode("name"), n))
+			}
+		default:
+			continue
+		}
+
+		value, err := prompt(path, property.Description)
+		if err != nil {
+			return err
+		}
+		setMappingValue(root, field, wrap(stringNode(value)))
+	}
+	return nil
+}
+
+// localized returns the localized field of the value in the default language.
+func localized(value *yaml.Node) *yaml.Node {
+	return mappingNode(stringNode(string(corev1alpha1.DefaultLanguageCode)), value)
+}
+
+func mappingNode(content ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: content}
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// setMappingValue sets the value of the entry of the mapping node, the entry is appended when there is no such entry.
+func setMappingValue(n *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content[i+1] = value
+			return
+		}
+	}
+	n.Content = append(n.Content, stringNode(key), value)
+}
@@ -0,0 +1,146 @@
+package api
+
+import "testing"
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// answers are the values the prompt answers by the paths it asks for
+		answers  map[string]string
+		want     string
+		wantFrom string
+		wantErr  bool
+	}{
+		{
+			name: "without apiVersion",
+			data: `# the demo extension
+
+name: demo # the name of the chart
+version: 0.1.0
+displayName:
+    en: Demo
+description:
+    en: A demo extension
+category: dev-tools
+provider:
+    en:
+        name: KubeSphere
+icon: ./static/favicon.svg
+`,
+			want: `# the demo extension
+
+apiVersion: kubesphere.io/v1alpha1
+name: demo # the name of the chart
+version: 0.1.0
+displayName:
+    en: Demo
+description:
+    en: A demo extension
+category: dev-tools
+provider:
+    en:
+        name: KubeSphere
+icon: ./static/favicon.svg
+`,
+			wantFrom: "",
+		},
+		{
+			name: "without required fields",
+			data: `name: demo
+version: 0.1.0
+description:
+  en: A demo extension
+icon: ./static/favicon.svg
+`,
+			answers: map[string]string{"displayName.en": "Demo", "category": "dev-tools", "provider.en.name": "KubeSphere"},
+			want: `apiVersion: kubesphere.io/v1alpha1
+name: demo
+version: 0.1.0
+description:
+  en: A demo extension
+icon: ./static/favicon.svg
+displayName:
+  en: Demo
+category: dev-tools
+provider:
+  en:
+    name: KubeSphere
+`,
+			wantFrom: "",
+		},
+		{
+			name: "at the current version",
+			data: `apiVersion: kubesphere.io/v1alpha1
+name:   demo
+`,
+			want: `apiVersion: kubesphere.io/v1alpha1
+name:   demo
+`,
+			wantFrom: APIVersion,
+		},
+		{
+			name:    "at an unknown version",
+			data:    "apiVersion: kubesphere.io/v1\nname: demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "not a mapping",
+			data:    "- name: demo\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := func(path, description string) (string, error) {
+				answer, ok := tt.answers[path]
+				if !ok {
+					t.Errorf("unexpected prompt for %s", path)
+				}
+				return answer, nil
+			}
+			got, from, err := Migrate([]byte(tt.data), prompt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Migrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Migrate() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("Migrate() migrated from %q, want %q", from, tt.wantFrom)
+			}
+		})
+	}
+}
+
+func TestMigrateRoundTrip(t *testing.T) {
+	data := []byte(`name: demo
+version: 0.1.0
+displayName:
+  en: Demo
+description:
+  en: A demo extension
+category: dev-tools
+provider:
+  en:
+    name: KubeSphere
+icon: ./static/favicon.svg
+`)
+	migrated, _, err := Migrate(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseMetadata(migrated); err != nil {
+		t.Errorf("the migrated file is invalid: %v", err)
+	}
+	again, from, err := Migrate(migrated, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != APIVersion || string(again) != string(migrated) {
+		t.Errorf("migrating the migrated file again = %q from %q, want it unchanged", again, from)
+	}
+}
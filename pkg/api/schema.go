@@ -0,0 +1,287 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// MetadataSchema is the JSON Schema of extension.yaml, which is published as pkg/api/extension.schema.json
+// for editors to complete and check extension.yaml.
+//
+//go:embed extension.schema.json
+var MetadataSchema []byte
+
+// metadataSchema is MetadataSchema with the references resolved, because the validator doesn't support them.
+var metadataSchema = sync.OnceValue(func() *spec.Schema {
+	schema := &spec.Schema{}
+	if err := json.Unmarshal(MetadataSchema, schema); err != nil {
+		panic(fmt.Sprintf("invalid schema of %s: %v", MetadataFilename, err))
+	}
+	resolveRefs(schema, schema.Definitions)
+	schema.Definitions = nil
+	return schema
+})
+
+// metadataValidator validates extension.yaml against MetadataSchema.
+var metadataValidator = sync.OnceValue(func() *validate.SchemaValidator {
+	return validate.NewSchemaValidator(metadataSchema(), nil, "", strfmt.Default)
+})
+
+// resolveRefs replaces the references to the definitions in the schema with the definitions.
+func resolveRefs(s *spec.Schema, definitions spec.Definitions) {
+	if ref := s.Ref.String(); ref != "" {
+		definition, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")]
+		if !ok {
+			panic(fmt.Sprintf("unknown reference %s in the schema of %s", ref, MetadataFilename))
+		}
+		*s = definition
+	}
+	for name, p := range s.Properties {
+		resolveRefs(&p, definitions)
+		s.Properties[name] = p
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		resolveRefs(s.Items.Schema, definitions)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		resolveRefs(s.AdditionalProperties.Schema, definitions)
+	}
+}
+
+// FieldError is a problem of a field of extension.yaml.
+type FieldError struct {
+	// Path is the path of the field by the YAML keys, e.g. provider.en.email or screenshots[0]
+	Path string
+	// Line and Column are the position of the field in extension.yaml, 0 when it is unknown
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	b.WriteString(MetadataFilename)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", e.Line, e.Column)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, ": %s", e.Path)
+	}
+	fmt.Fprintf(&b, ": %s", e.Message)
+	return b.String()
+}
+
+// FieldErrors are all the problems of extension.yaml, sorted by position.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, fe := range e {
+		lines = append(lines, fe.Error())
+	}
+	return fmt.Sprintf("%d problem(s) found:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// validateMetadataSchema validates extension.yaml, whose YAML document is given, against MetadataSchema
+// and returns all problems with their positions.
+func validateMetadataSchema(document *yaml.Node, data []byte) (FieldErrors, error) {
+	if len(document.Content) == 0 {
+		return nil, &FieldError{Message: "is empty"}
+	}
+	// the other problems are of no use when the file is written in another version of the schema
+	if err := checkAPIVersion(document.Content[0]); err != nil {
+		return nil, err
+	}
+	jsonData, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var object any
+	if err := json.Unmarshal(jsonData, &object); err != nil {
+		return nil, err
+	}
+
+	var result FieldErrors
+	for _, e := range metadataValidator().Validate(object).Errors {
+		var ve *openapierrors.Validation
+		if !errors.As(e, &ve) {
+			result = append(result, &FieldError{Message: e.Error()})
+			continue
+		}
+		fe := &FieldError{Path: strings.TrimPrefix(ve.Name, ".")}
+		switch ve.Code() {
+		case openapierrors.UnallowedPropertyCode:
+			fe.Path = strings.TrimPrefix(fmt.Sprintf("%s.%v", fe.Path, ve.Value), ".")
+			fe.Message = "unknown field"
+			fe.locate(document.Content[0], locateKey)
+		case openapierrors.RequiredFailCode:
+			fe.Message = "required"
+			if fe.Path == "apiVersion" {
+				fe.Message = fmt.Sprintf("required, run ksbuilder migrate to set it to %s", APIVersion)
+			}
+			fe.locate(document.Content[0], locateParent)
+		default:
+			// the messages of the validator start with the path of the field
+			message := strings.Replace(e.Error(), " in body", "", 1)
+			fe.Message = readableMessage(strings.TrimSpace(strings.TrimPrefix(message, ve.Name)))
+			fe.locate(document.Content[0], locateValue)
+		}
+		result = append(result, fe)
+	}
+	return result, nil
+}
+
+// decodeMetadata decodes extension.yaml as far as it can. The values of the wrong types are left empty,
+// and returned as a *json.UnmarshalTypeError of the first one.
+func decodeMetadata(data []byte) (*Metadata, error) {
+	jsonData, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	metadata := new(Metadata)
+	if err = json.Unmarshal(jsonData, metadata); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if !errors.As(err, &typeError) {
+			return nil, err
+		}
+	}
+	return metadata, err
+}
+
+// mergeFieldErrors merges the problems Validate reports into the ones of MetadataSchema, sorted by position.
+// The problems of the fields MetadataSchema already reports are left out, since they follow from those,
+// e.g. a version of the wrong type is decoded as empty and required again.
+func mergeFieldErrors(schemaErrors, errs FieldErrors) FieldErrors {
+	result := slices.Clone(schemaErrors)
+	for _, e := range errs {
+		reported := slices.ContainsFunc(schemaErrors, func(se *FieldError) bool {
+			return se.Path == e.Path || strings.HasPrefix(e.Path, se.Path+".") || strings.HasPrefix(e.Path, se.Path+"[")
+		})
+		if !reported {
+			result = append(result, e)
+		}
+	}
+	sortFieldErrors(result)
+	return result
+}
+
+// sortFieldErrors sorts the problems by position, those of unknown positions stay where they are.
+func sortFieldErrors(errs FieldErrors) {
+	slices.SortStableFunc(errs, func(a, b *FieldError) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+}
+
+// typeMismatch matches the messages of the validator for values of the wrong type, e.g. must be of type string: "number"
+var typeMismatch = regexp.MustCompile(`^must be of type ([\w,]+): "(\w+)"$`)
+
+// readableMessage rewords the messages of the validator for authors of extension.yaml.
+func readableMessage(message string) string {
+	m := typeMismatch.FindStringSubmatch(message)
+	switch {
+	case m == nil:
+		return message
+	case m[2] == "null":
+		return fmt.Sprintf("must be %s, but is empty", withArticle(m[1]))
+	default:
+		return fmt.Sprintf("must be %s, not %s", withArticle(m[1]), withArticle(m[2]))
+	}
+}
+
+// withArticle prefixes the name of a type with an indefinite article, e.g. an object
+func withArticle(typeName string) string {
+	if strings.ContainsAny(typeName[:1], "aeiou") {
+		return "an " + typeName
+	}
+	return "a " + typeName
+}
+
+// locateAt tells what a FieldError is located at.
+type locateAt int
+
+const (
+	// locateValue locates the value of the field
+	locateValue locateAt = iota
+	// locateKey locates the key of the field, for the fields that are unknown or whose keys are invalid
+	locateKey
+	// locateParent locates the object the field is missing from
+	locateParent
+)
+
+// locate sets the position of the field in the YAML node of extension.yaml, which is left unknown without the node.
+func (e *FieldError) locate(root *yaml.Node, at locateAt) {
+	if root == nil {
+		return
+	}
+	switch at {
+	case locateKey:
+		e.Line, e.Column = position(root, e.Path, true)
+	case locateParent:
+		parent := e.Path[:max(strings.LastIndexAny(e.Path, ".["), 0)]
+		e.Line, e.Column = position(root, parent, false)
+	default:
+		e.Line, e.Column = position(root, e.Path, false)
+	}
+}
+
+// pathIndex matches an index of a list at the start of a path, e.g. [0].name
+var pathIndex = regexp.MustCompile(`^\[(\d+)\]`)
+
+// position returns the line and column of the value at the path in the YAML node, or of its key when key is set.
+// The keys of maps may contain dots, such as the annotations, so the longest key matching the path is taken.
+func position(n *yaml.Node, path string, key bool) (int, int) {
+	keyNode := n
+	for path != "" {
+		path = strings.TrimPrefix(path, ".")
+		switch n.Kind {
+		case yaml.MappingNode:
+			match := -1
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k := n.Content[i].Value
+				if (path == k || strings.HasPrefix(path, k+".") || strings.HasPrefix(path, k+"[")) &&
+					(match < 0 || len(k) > len(n.Content[match].Value)) {
+					match = i
+				}
+			}
+			if match < 0 {
+				return n.Line, n.Column
+			}
+			path = strings.TrimPrefix(path, n.Content[match].Value)
+			keyNode, n = n.Content[match], n.Content[match+1]
+		case yaml.SequenceNode:
+			m := pathIndex.FindStringSubmatch(path)
+			if m == nil {
+				return n.Line, n.Column
+			}
+			i, _ := strconv.Atoi(m[1])
+			if i >= len(n.Content) {
+				return n.Line, n.Column
+			}
+			path = strings.TrimPrefix(path, m[0])
+			keyNode, n = n, n.Content[i]
+		default:
+			return n.Line, n.Column
+		}
+	}
+	if key {
+		return keyNode.Line, keyNode.Column
+	}
+	return n.Line, n.Column
+}
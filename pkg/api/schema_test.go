@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFieldErrorLocate(t *testing.T) {
+	const data = `apiVersion: kubesphere.io/v1alpha1
+name: demo
+provider:
+  en:
+    name: Demo
+    email: demo
+maintainers:
+  - name: admin
+    email: admin
+annotations:
+  example.com/owner.name: admin
+`
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+		t.Fatal(err)
+	}
+	root := document.Content[0]
+
+	tests := []struct {
+		name         string
+		path         string
+		at           locateAt
+		line, column int
+	}{
+		{name: "a value", path: "provider.en.email", at: locateValue, line: 6, column: 12},
+		{name: "a key", path: "provider.en.email", at: locateKey, line: 6, column: 5},
+		{name: "a value in a list", path: "maintainers[0].email", at: locateValue, line: 9, column: 12},
+		{name: "a key with dots", path: "annotations.example.com/owner.name", at: locateKey, line: 11, column: 3},
+		{name: "a missing field", path: "provider.en.url", at: locateParent, line: 5, column: 5},
+		{name: "a missing top level field", path: "version", at: locateParent, line: 1, column: 1},
+		{name: "under a missing field", path: "provider.zh.name", at: locateValue, line: 4, column: 3},
+		{name: "out of a list", path: "maintainers[3].name", at: locateValue, line: 8, column: 3},
+		{name: "under a scalar", path: "name.en", at: locateValue, line: 2, column: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &FieldError{Path: tt.path}
+			e.locate(root, tt.at)
+			if e.Line != tt.line || e.Column != tt.column {
+				t.Errorf("%s is located at %d:%d, want %d:%d", tt.path, e.Line, e.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestFieldErrorLocateWithoutDocument(t *testing.T) {
+	e := &FieldError{Path: "name"}
+	e.locate(nil, locateValue)
+	if e.Line != 0 || e.Column != 0 {
+		t.Errorf("located at %d:%d without document, want unknown", e.Line, e.Column)
+	}
+}
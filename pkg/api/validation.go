@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/util/validation"
+	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
+
+	"github.com/kubesphere/ksbuilder/pkg/iso639"
+)
+
+// metadataType is the type the paths of validation errors are resolved against.
+var metadataType = reflect.TypeOf(Metadata{})
+
+// newValidator returns a validator that names fields by their YAML keys, with the checks of extension.yaml
+// that the validator doesn't have built in.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// the errors of these checks are ignored, since the checks can't be misused by the tags of Metadata
+	_ = v.RegisterValidation("category", func(fl validator.FieldLevel) bool {
+		return slices.Contains(categoryNames(), fl.Field().String())
+	})
+	_ = v.RegisterValidation("dns1123_label", func(fl validator.FieldLevel) bool {
+		return len(validation.IsDNS1123Label(fl.Field().String())) == 0
+	})
+	_ = v.RegisterValidation("annotation_key", func(fl validator.FieldLevel) bool {
+		return len(validation.IsQualifiedName(fl.Field().String())) == 0
+	})
+	// the maintainers are defined by helm, so their emails are checked by the struct rather than a tag
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		m := sl.Current().Interface().(chart.Maintainer)
+		if m.Email != "" && sl.Validator().Var(m.Email, "email") != nil {
+			sl.ReportError(m.Email, "email", "Email", "email", "")
+		}
+	}, chart.Maintainer{})
+	return v
+}
+
+// Validate reports all the problems of the metadata at once, as FieldErrors. The problems are located
+// in extension.yaml when the metadata is parsed by ParseMetadata.
+func (md *Metadata) Validate() error {
+	var result FieldErrors
+	if err := newValidator().Struct(md); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+		for _, fe := range validationErrors {
+			e := &FieldError{
+				Path:    yamlPath(fe.Namespace()),
+				Message: validationMessage(fe),
+			}
+			switch fe.Tag() {
+			case "required":
+				e.locate(md.document, locateParent)
+			case "annotation_key":
+				e.locate(md.document, locateKey)
+			default:
+				e.locate(md.document, locateValue)
+			}
+			result = append(result, e)
+		}
+	}
+	result = append(result, md.validateLanguageCodes()...)
+	if len(result) == 0 {
+		return nil
+	}
+	sortFieldErrors(result)
+	return result
+}
+
+// validateLanguageCodes reports the keys of the locale maps that are not language codes.
+func (md *Metadata) validateLanguageCodes() FieldErrors {
+	var result FieldErrors
+	check := func(field string, codes []corev1alpha1.LanguageCode) {
+		for _, code := range codes {
+			if !iso639.IsValidLanguageCode(code) {
+				e := &FieldError{
+					Path:    fmt.Sprintf("%s.%s", field, code),
+					Message: "invalid language code, see https://www.loc.gov/standards/iso639-2/php/code_list.php for more details",
+				}
+				e.locate(md.document, locateKey)
+				result = append(result, e)
+			}
+		}
+	}
+	check("displayName", slices.Sorted(maps.Keys(md.DisplayName)))
+	check("description", slices.Sorted(maps.Keys(md.Description)))
+	check("provider", slices.Sorted(maps.Keys(md.Provider)))
+	return result
+}
+
+// validationMessage describes the failed check of a field.
+func validationMessage(fe validator.FieldError) string {
+	value := fmt.Sprint(fe.Value())
+	switch fe.Tag() {
+	case "required":
+		return "required"
+	case "semver":
+		return fmt.Sprintf("%q is not a semantic version, e.g. 1.0.0", value)
+	case "http_url":
+		return fmt.Sprintf("%q is not an http or https URL", value)
+	case "email":
+		return fmt.Sprintf("%q is not an email address", value)
+	case "oneof":
+		return fmt.Sprintf("%q is not one of %s", value, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "category":
+		return fmt.Sprintf("%q is not one of the categories %s, see ksbuilder category", value, strings.Join(categoryNames(), ", "))
+	case "dns1123_label":
+		return fmt.Sprintf("%q is invalid: %s", value, strings.Join(validation.IsDNS1123Label(value), "; "))
+	case "annotation_key":
+		return fmt.Sprintf("the key %q is invalid: %s", value, strings.Join(validation.IsQualifiedName(value), "; "))
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("failed the %s=%s check", fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("failed the %s check", fe.Tag())
+}
+
+// yamlPath converts the namespace of a validation error to the path of the field by the YAML keys,
+// the keys of maps are separated by dots and the indexes of lists are in brackets like MetadataSchema errors,
+// e.g. Metadata.provider[en].email -> provider.en.email and Metadata.sources[0] -> sources[0]
+func yamlPath(namespace string) string {
+	// the namespace starts with the name of the struct
+	_, rest, _ := strings.Cut(namespace, ".")
+
+	var b strings.Builder
+	t := metadataType
+	for rest != "" {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if key, ok := strings.CutPrefix(rest, "["); ok {
+			// map keys may contain dots, but not brackets
+			key, rest, _ = strings.Cut(key, "]")
+			switch t.Kind() {
+			case reflect.Map:
+				fmt.Fprintf(&b, ".%s", key)
+			default:
+				fmt.Fprintf(&b, "[%s]", key)
+			}
+			t = t.Elem()
+			continue
+		}
+
+		rest = strings.TrimPrefix(rest, ".")
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(name)
+		if t.Kind() == reflect.Struct {
+			if f, ok := fieldByYAMLKey(t, name); ok {
+				t = f.Type
+			}
+		}
+	}
+	return b.String()
+}
+
+// fieldByYAMLKey returns the field of the struct named by the YAML key.
+func fieldByYAMLKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for _, f := range reflect.VisibleFields(t) {
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
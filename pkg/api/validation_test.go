@@ -0,0 +1,26 @@
+package api
+
+import "testing"
+
+func TestYAMLPath(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{namespace: "Metadata.name", want: "name"},
+		{namespace: "Metadata.sources[0]", want: "sources[0]"},
+		{namespace: "Metadata.displayName[en]", want: "displayName.en"},
+		{namespace: "Metadata.provider[en].email", want: "provider.en.email"},
+		{namespace: "Metadata.maintainers[1].email", want: "maintainers[1].email"},
+		{namespace: "Metadata.dependencies[0].version", want: "dependencies[0].version"},
+		// the keys of maps may contain dots
+		{namespace: "Metadata.annotations[example.com/owner.name]", want: "annotations.example.com/owner.name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if got := yamlPath(tt.namespace); got != tt.want {
+				t.Errorf("yamlPath(%q) = %q, want %q", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
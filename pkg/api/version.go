@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIVersion is the current version of the extension.yaml schema, the files written before the schema was versioned
+// have no apiVersion and are migrated to it.
+const APIVersion = "kubesphere.io/v1alpha1"
+
+// checkAPIVersion refuses the versions of the schema ksbuilder doesn't know.
+func checkAPIVersion(root *yaml.Node) error {
+	keyNode, n := mappingEntry(root, "apiVersion")
+	// a missing or invalid apiVersion is reported against MetadataSchema
+	if n == nil || n.Kind != yaml.ScalarNode || n.Tag != "!!str" || n.Value == "" || n.Value == APIVersion {
+		return nil
+	}
+	return &FieldError{
+		Path:   keyNode.Value,
+		Line:   n.Line,
+		Column: n.Column,
+		Message: fmt.Sprintf("%q is not a version of the schema ksbuilder knows, the current one is %s, "+
+			"upgrade ksbuilder if the extension is written for a newer one", n.Value, APIVersion),
+	}
+}
+
+// mappingEntry returns the key and the value of the entry of the mapping node, or nils when there is no such entry.
+func mappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,224 @@
+package extension
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+const (
+	// maxIconSize limits the icon, which is embedded into the extension resources as a data URL.
+	maxIconSize = 100 * 1024
+	// maxScreenshotSize limits every screenshot uploaded to the marketplace.
+	maxScreenshotSize = 2 * 1024 * 1024
+	// minScreenshotWidth and minScreenshotHeight are the smallest screenshots readable in the marketplace.
+	minScreenshotWidth  = 800
+	minScreenshotHeight = 450
+	// minScreenshotAspect and maxScreenshotAspect bound the width to height ratio of screenshots, from 1:1 to 4:1.
+	minScreenshotAspect = 1.0
+	maxScreenshotAspect = 4.0
+)
+
+var (
+	iconTypes       = []string{"image/svg+xml", "image/png", "image/jpeg", "image/gif"}
+	screenshotTypes = []string{"image/png", "image/jpeg", "image/gif"}
+)
+
+// staticAsset is a local file extension.yaml refers to.
+type staticAsset struct {
+	// field is the field of extension.yaml referring to the file
+	field string
+	// name is the path of the file relative to the extension directory
+	name string
+	data []byte
+}
+
+// staticAssets returns the local icon and screenshots of the extension, and the findings of those that are not found.
+// They are read from the extension directory like LoadMetadata and push do, rather than from the chart files, which
+// leave out the files .helmignore matches, such as the staticFileDirectory that push doesn't package into the chart.
+func (c *lintContext) staticAssets() ([]staticAsset, []lint.Finding) {
+	refs := []staticAsset{{field: "icon", name: c.metadata.Icon}}
+	for i, s := range c.metadata.Screenshots {
+		refs = append(refs, staticAsset{field: fmt.Sprintf("screenshots[%d]", i), name: s})
+	}
+
+	var assets []staticAsset
+	var missing []lint.Finding
+	for _, a := range refs {
+		if a.name == "" || !api.IsLocalFile(a.name) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(a.name)))
+		if err != nil {
+			missing = append(missing, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("%s %s is not found", a.field, a.name),
+			})
+			continue
+		}
+		a.name, a.data = path.Clean(a.name), data
+		assets = append(assets, a)
+	}
+	return assets, missing
+}
+
+// contentType returns the MIME type of the asset by its extension, and the MIME type sniffed from its content.
+func (a staticAsset) contentType() (string, string) {
+	byExtension, _, _ := mime.ParseMediaType(mime.TypeByExtension(path.Ext(a.name)))
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(a.data))
+	return byExtension, sniffed
+}
+
+// lintStaticAssets reports the local icon and screenshots that are missing, of unsupported types or unsafe SVG,
+// and those outside of the staticFileDirectory.
+func lintStaticAssets(c *lintContext) ([]lint.Finding, error) {
+	assets, findings := c.staticAssets()
+	report := func(a staticAsset, format string, args ...any) {
+		findings = append(findings, lint.Finding{
+			File:    a.name,
+			Message: fmt.Sprintf("%s: %s", a.field, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	for _, a := range assets {
+		allowed := iconTypes
+		if a.field != "icon" {
+			allowed = screenshotTypes
+		}
+		byExtension, sniffed := a.contentType()
+		switch {
+		case !slices.Contains(allowed, byExtension):
+			report(a, "type %q is not supported, must be one of %s", byExtension, strings.Join(allowed, ", "))
+		case byExtension == "image/svg+xml":
+			for _, issue := range unsafeSVG(a.data) {
+				report(a, "SVG is unsafe: %s", issue)
+			}
+		case byExtension != sniffed:
+			report(a, "content of type %q doesn't match the file extension %s", sniffed, path.Ext(a.name))
+		}
+	}
+
+	if dir := c.metadata.StaticFileDirectory; dir != "" {
+		dir = path.Clean(dir)
+		if entries, err := os.ReadDir(filepath.Join(c.dir, filepath.FromSlash(dir))); err != nil || len(entries) == 0 {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("staticFileDirectory %s is not found or empty", c.metadata.StaticFileDirectory),
+			})
+		}
+		for _, a := range assets {
+			if !strings.HasPrefix(a.name, dir+"/") {
+				findings = append(findings, lint.Finding{
+					File: api.MetadataFilename,
+					Message: fmt.Sprintf("%s %s is not in the staticFileDirectory %s, it will be packaged into the chart",
+						a.field, a.name, c.metadata.StaticFileDirectory),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// lintStaticAssetSizes reports the local icon and screenshots that are too large, and the screenshots
+// of unsuitable dimensions.
+func lintStaticAssetSizes(c *lintContext) ([]lint.Finding, error) {
+	// missing assets are reported by the static-assets rule
+	assets, _ := c.staticAssets()
+
+	var findings []lint.Finding
+	report := func(a staticAsset, format string, args ...any) {
+		findings = append(findings, lint.Finding{
+			File:    a.name,
+			Message: fmt.Sprintf("%s: %s", a.field, fmt.Sprintf(format, args...)),
+		})
+	}
+	for _, a := range assets {
+		limit := maxScreenshotSize
+		if a.field == "icon" {
+			limit = maxIconSize
+		}
+		if len(a.data) > limit {
+			report(a, "size %d KiB exceeds the limit of %d KiB", len(a.data)/1024, limit/1024)
+		}
+		if a.field == "icon" {
+			continue
+		}
+
+		config, _, err := image.DecodeConfig(bytes.NewReader(a.data))
+		if err != nil {
+			// unsupported types are reported by the static-assets rule
+			continue
+		}
+		if config.Width < minScreenshotWidth || config.Height < minScreenshotHeight {
+			report(a, "dimensions %dx%d are smaller than %dx%d", config.Width, config.Height, minScreenshotWidth, minScreenshotHeight)
+		}
+		if aspect := float64(config.Width) / float64(config.Height); aspect < minScreenshotAspect || aspect > maxScreenshotAspect {
+			report(a, "aspect ratio %.2f of %dx%d is out of the range from 1:1 to 4:1", aspect, config.Width, config.Height)
+		}
+	}
+	return findings, nil
+}
+
+// svgExternalURL matches the url() references to other documents in SVG styles.
+var svgExternalURL = regexp.MustCompile(`(?i)(url\(\s*['"]?\s*(https?:|//|javascript:)|@import)`)
+
+// unsafeSVG returns the scripts and external references in an SVG document, which the console can't render safely.
+func unsafeSVG(data []byte) []string {
+	issues := sets.New[string]()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			issues.Insert(fmt.Sprintf("unable to parse: %v", err))
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "script":
+				issues.Insert("<script> elements are not allowed")
+			case "foreignobject":
+				issues.Insert("<foreignObject> elements are not allowed")
+			}
+			for _, attr := range t.Attr {
+				name, value := strings.ToLower(attr.Name.Local), strings.TrimSpace(attr.Value)
+				switch {
+				case strings.HasPrefix(name, "on"):
+					issues.Insert(fmt.Sprintf("event handler attribute %s is not allowed", attr.Name.Local))
+				case (name == "href" || name == "src") && value != "" && !strings.HasPrefix(value, "#") && !strings.HasPrefix(value, "data:image/"):
+					issues.Insert(fmt.Sprintf("external reference %s is not allowed", value))
+				case name == "style" && svgExternalURL.MatchString(value):
+					issues.Insert("external references in styles are not allowed")
+				}
+			}
+		case xml.CharData:
+			if svgExternalURL.Match(t) {
+				issues.Insert("external references in styles are not allowed")
+			}
+		}
+	}
+	return sets.List(issues)
+}
@@ -0,0 +1,224 @@
+package extension
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// dependencyTypeExtension is the type of the external dependencies on other extensions, also the default one.
+const dependencyTypeExtension = "extension"
+
+// knownDependencyTypes are the types of external dependencies KubeSphere can resolve.
+var knownDependencyTypes = []string{dependencyTypeExtension}
+
+// lintExternalDependencies reports the external dependencies that can't be resolved whatever the other extensions are.
+func lintExternalDependencies(c *lintContext) ([]lint.Finding, error) {
+	var findings []lint.Finding
+	report := func(format string, args ...any) {
+		findings = append(findings, lint.Finding{
+			File:    api.MetadataFilename,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := sets.New[string]()
+	for _, d := range c.metadata.ExternalDependencies {
+		for _, msg := range validation.IsDNS1123Subdomain(d.Name) {
+			report("external dependency name %q is invalid: %s", d.Name, msg)
+		}
+		if seen.Has(d.Name) {
+			report("external dependency %s is declared more than once", d.Name)
+		}
+		seen.Insert(d.Name)
+		if d.Name == c.metadata.Name {
+			report("extension %s depends on itself", d.Name)
+		}
+		if _, err := semver.NewConstraint(d.Version); err != nil {
+			report("version %q of external dependency %s is not a valid semver constraint: %v", d.Version, d.Name, err)
+		}
+		if d.Type != "" && !slices.Contains(knownDependencyTypes, d.Type) {
+			report("type %q of external dependency %s is unknown, must be one of %s", d.Type, d.Name, strings.Join(knownDependencyTypes, ", "))
+		}
+	}
+	return findings, nil
+}
+
+// catalogEntry is a version of another extension, by the fields of its extension.yaml the dependency graph needs.
+type catalogEntry struct {
+	Name                 string                            `json:"name"`
+	Version              string                            `json:"version"`
+	ExternalDependencies []corev1alpha1.ExternalDependency `json:"externalDependencies,omitempty"`
+}
+
+// catalog is the versions of other extensions, keyed by extension name.
+type catalog map[string][]*catalogEntry
+
+// loadCatalog reads all extension.yaml files under dir. Only the fields the dependency graph needs are read,
+// so the extensions written for other versions of ksbuilder are still resolved. The files that can't be read
+// are reported as findings and left out of the catalog.
+func loadCatalog(dir string) (catalog, []lint.Finding, error) {
+	result := make(catalog)
+	var findings []lint.Finding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != api.MetadataFilename {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entry := new(catalogEntry)
+		var problem string
+		switch err = yaml.Unmarshal(data, entry); {
+		case err != nil:
+			problem = err.Error()
+		case entry.Name == "":
+			problem = "name is missing"
+		case entry.Version == "":
+			problem = "version is missing"
+		}
+		if problem != "" {
+			findings = append(findings, lint.Finding{
+				Severity:     lint.SeverityWarning,
+				KeepSeverity: true,
+				Message:      fmt.Sprintf("catalog entry %s is left out: %s", path, problem),
+			})
+			return nil
+		}
+		result[entry.Name] = append(result[entry.Name], entry)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load catalog %s: %w", dir, err)
+	}
+	return result, findings, nil
+}
+
+// resolve returns the latest version of the dependency in the catalog that satisfies its version constraint,
+// and the versions available in the catalog.
+func (c catalog) resolve(d corev1alpha1.ExternalDependency) (*catalogEntry, []string) {
+	constraint, err := semver.NewConstraint(d.Version)
+	if err != nil {
+		return nil, nil
+	}
+	var resolved *catalogEntry
+	var resolvedVersion *semver.Version
+	var available []string
+	for _, md := range c[d.Name] {
+		available = append(available, md.Version)
+		v, err := semver.NewVersion(md.Version)
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+		if resolvedVersion == nil || v.GreaterThan(resolvedVersion) {
+			resolved, resolvedVersion = md, v
+		}
+	}
+	return resolved, available
+}
+
+// lintExternalDependencyGraph resolves the external dependencies against the extensions of the --catalog directory,
+// and reports the dependencies that are missing or unsatisfiable and the dependency cycles.
+func lintExternalDependencyGraph(c *lintContext) ([]lint.Finding, error) {
+	if c.options.Catalog == "" {
+		return nil, nil
+	}
+	extensions, findings, err := loadCatalog(c.options.Catalog)
+	if err != nil {
+		return nil, err
+	}
+	// the linted extension takes the place of its versions in the catalog
+	root := &catalogEntry{
+		Name:                 c.metadata.Name,
+		Version:              c.metadata.Version,
+		ExternalDependencies: c.metadata.ExternalDependencies,
+	}
+	extensions[root.Name] = []*catalogEntry{root}
+
+	for _, d := range c.metadata.ExternalDependencies {
+		if (d.Type != "" && d.Type != dependencyTypeExtension) || d.Name == c.metadata.Name {
+			continue
+		}
+		if _, err := semver.NewConstraint(d.Version); err != nil || len(validation.IsDNS1123Subdomain(d.Name)) > 0 {
+			// reported by the external-dependencies rule
+			continue
+		}
+		resolved, available := extensions.resolve(d)
+		switch {
+		case len(available) == 0:
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("external dependency %s is not found in the catalog", d.Name),
+			})
+		case resolved == nil:
+			findings = append(findings, lint.Finding{
+				File: api.MetadataFilename,
+				Message: fmt.Sprintf("no version of external dependency %s satisfies %q, the catalog has [%s]",
+					d.Name, d.Version, strings.Join(available, ", ")),
+			})
+		}
+	}
+
+	for _, cycle := range extensions.cycles(root) {
+		findings = append(findings, lint.Finding{
+			File:    api.MetadataFilename,
+			Message: fmt.Sprintf("external dependencies form a cycle: %s", strings.Join(cycle, " -> ")),
+		})
+	}
+	return findings, nil
+}
+
+// cycles returns the dependency cycles reachable from the extension, following the resolved extension dependencies.
+// Every cycle starts and ends with the same extension.
+func (c catalog) cycles(root *catalogEntry) [][]string {
+	var result [][]string
+	reported := sets.New[string]()
+	done := sets.New[string]()
+	var stack []string
+
+	var visit func(md *catalogEntry)
+	visit = func(md *catalogEntry) {
+		if i := slices.Index(stack, md.Name); i >= 0 {
+			cycle := append(slices.Clone(stack[i:]), md.Name)
+			// the same cycle may be entered from any of its extensions
+			key := strings.Join(slices.Sorted(slices.Values(stack[i:])), ",")
+			if !reported.Has(key) {
+				reported.Insert(key)
+				result = append(result, cycle)
+			}
+			return
+		}
+		if done.Has(md.Name) {
+			return
+		}
+		stack = append(stack, md.Name)
+		for _, d := range md.ExternalDependencies {
+			if d.Type != "" && d.Type != dependencyTypeExtension {
+				continue
+			}
+			if resolved, _ := c.resolve(d); resolved != nil {
+				visit(resolved)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		done.Insert(md.Name)
+	}
+	visit(root)
+	return result
+}
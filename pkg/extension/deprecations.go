@@ -0,0 +1,122 @@
+package extension
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// apiLifecycle is when an API of a kind is deprecated and removed, a zero minor version means never.
+type apiLifecycle struct {
+	deprecatedMinor int
+	removedMinor    int
+	// replacement is the API version to migrate to, empty when there is none
+	replacement string
+}
+
+// removedAPIs are the lifecycles of the APIs removed from Kubernetes 1.x, by the deprecated API migration guide
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+// client-go no longer registers the types of these APIs, so they can't be looked up by the scheme.
+var removedAPIs = map[schema.GroupVersionKind]apiLifecycle{}
+
+func init() {
+	add := func(groupVersion string, kinds []string, deprecatedMinor, removedMinor int, replacement string) {
+		gv := schema.FromAPIVersionAndKind(groupVersion, "").GroupVersion()
+		for _, kind := range kinds {
+			removedAPIs[gv.WithKind(kind)] = apiLifecycle{deprecatedMinor: deprecatedMinor, removedMinor: removedMinor, replacement: replacement}
+		}
+	}
+
+	// 1.16
+	add("extensions/v1beta1", []string{"DaemonSet", "Deployment", "ReplicaSet"}, 9, 16, "apps/v1")
+	add("extensions/v1beta1", []string{"NetworkPolicy"}, 9, 16, "networking.k8s.io/v1")
+	add("extensions/v1beta1", []string{"PodSecurityPolicy"}, 11, 16, "policy/v1beta1")
+	add("apps/v1beta1", []string{"Deployment", "StatefulSet", "ControllerRevision"}, 9, 16, "apps/v1")
+	add("apps/v1beta2", []string{"DaemonSet", "Deployment", "ReplicaSet", "StatefulSet", "ControllerRevision"}, 9, 16, "apps/v1")
+	// 1.22
+	add("admissionregistration.k8s.io/v1beta1", []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, 16, 22, "admissionregistration.k8s.io/v1")
+	add("apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, 16, 22, "apiextensions.k8s.io/v1")
+	add("apiregistration.k8s.io/v1beta1", []string{"APIService"}, 19, 22, "apiregistration.k8s.io/v1")
+	add("authentication.k8s.io/v1beta1", []string{"TokenReview"}, 19, 22, "authentication.k8s.io/v1")
+	add("authorization.k8s.io/v1beta1", []string{"LocalSubjectAccessReview", "SelfSubjectAccessReview", "SubjectAccessReview"}, 19, 22, "authorization.k8s.io/v1")
+	add("certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, 19, 22, "certificates.k8s.io/v1")
+	add("coordination.k8s.io/v1beta1", []string{"Lease"}, 19, 22, "coordination.k8s.io/v1")
+	add("extensions/v1beta1", []string{"Ingress"}, 14, 22, "networking.k8s.io/v1")
+	add("networking.k8s.io/v1beta1", []string{"Ingress", "IngressClass"}, 19, 22, "networking.k8s.io/v1")
+	add("rbac.authorization.k8s.io/v1beta1", []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, 17, 22, "rbac.authorization.k8s.io/v1")
+	add("scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, 14, 22, "scheduling.k8s.io/v1")
+	add("storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, 19, 22, "storage.k8s.io/v1")
+	// 1.25
+	add("batch/v1beta1", []string{"CronJob"}, 21, 25, "batch/v1")
+	add("discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, 21, 25, "discovery.k8s.io/v1")
+	add("events.k8s.io/v1beta1", []string{"Event"}, 19, 25, "events.k8s.io/v1")
+	add("autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, 22, 25, "autoscaling/v2")
+	add("policy/v1beta1", []string{"PodDisruptionBudget"}, 21, 25, "policy/v1")
+	add("policy/v1beta1", []string{"PodSecurityPolicy"}, 21, 25, "")
+	add("node.k8s.io/v1beta1", []string{"RuntimeClass"}, 20, 25, "node.k8s.io/v1")
+	// 1.26
+	add("flowcontrol.apiserver.k8s.io/v1beta1", []string{"FlowSchema", "PriorityLevelConfiguration"}, 23, 26, "flowcontrol.apiserver.k8s.io/v1")
+	add("autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, 23, 26, "autoscaling/v2")
+	// 1.27
+	add("storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, 24, 27, "storage.k8s.io/v1")
+	// 1.29
+	add("flowcontrol.apiserver.k8s.io/v1beta2", []string{"FlowSchema", "PriorityLevelConfiguration"}, 26, 29, "flowcontrol.apiserver.k8s.io/v1")
+	// 1.32
+	add("flowcontrol.apiserver.k8s.io/v1beta3", []string{"FlowSchema", "PriorityLevelConfiguration"}, 29, 32, "flowcontrol.apiserver.k8s.io/v1")
+}
+
+type apiLifecycleDeprecated interface {
+	APILifecycleDeprecated() (major, minor int)
+}
+
+type apiLifecycleRemoved interface {
+	APILifecycleRemoved() (major, minor int)
+}
+
+type apiLifecycleReplacement interface {
+	APILifecycleReplacement() schema.GroupVersionKind
+}
+
+// lifecycleOf returns the lifecycle of the API of the kind, by removedAPIs or the lifecycle of the types client-go
+// registers, which covers the APIs deprecated after this table was written. It returns false when the API is not
+// going to be removed, or is not a Kubernetes API.
+func lifecycleOf(gvk schema.GroupVersionKind) (apiLifecycle, bool) {
+	if l, ok := removedAPIs[gvk]; ok {
+		return l, true
+	}
+	object, err := kscheme.Scheme.New(gvk)
+	if err != nil {
+		return apiLifecycle{}, false
+	}
+
+	var l apiLifecycle
+	if d, ok := object.(apiLifecycleDeprecated); ok {
+		if major, minor := d.APILifecycleDeprecated(); major == 1 {
+			l.deprecatedMinor = minor
+		}
+	}
+	if r, ok := object.(apiLifecycleRemoved); ok {
+		if major, minor := r.APILifecycleRemoved(); major == 1 {
+			l.removedMinor = minor
+		}
+	}
+	if r, ok := object.(apiLifecycleReplacement); ok {
+		if replacement := r.APILifecycleReplacement(); !replacement.Empty() {
+			l.replacement = replacement.GroupVersion().String()
+		}
+	}
+	return l, l.deprecatedMinor > 0 || l.removedMinor > 0
+}
+
+// message describes the lifecycle of the API of the kind like the API server warns about it.
+func (l apiLifecycle) message(gvk schema.GroupVersionKind) string {
+	msg := fmt.Sprintf("%s %s is deprecated in v1.%d+", gvk.GroupVersion(), gvk.Kind, l.deprecatedMinor)
+	if l.removedMinor > 0 {
+		msg += fmt.Sprintf(", unavailable in v1.%d+", l.removedMinor)
+	}
+	if l.replacement != "" {
+		msg += fmt.Sprintf("; use %s %s", l.replacement, gvk.Kind)
+	}
+	return msg
+}
@@ -0,0 +1,328 @@
+package extension
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// extensionsGroup is the API group of the resources that wire an extension into the KubeSphere console and API.
+const extensionsGroup = "extensions.kubesphere.io"
+
+// jsBundleSources are the fields a JSBundle loads its script from, exactly one of them must be set.
+var jsBundleSources = []string{
+	"spec.raw",
+	"spec.rawFrom.url",
+	"spec.rawFrom.service",
+	"spec.rawFrom.configMapKeyRef",
+	"spec.rawFrom.secretKeyRef",
+}
+
+// jsBundleLinkPrefix is the path the console loads the scripts of JSBundles from.
+const jsBundleLinkPrefix = "/dist/"
+
+// extensionResource is a rendered resource of the extensions.kubesphere.io group.
+type extensionResource struct {
+	renderedManifest
+	kind string
+}
+
+// extensionResources renders the chart and returns the resources of the extensions.kubesphere.io group,
+// and all rendered manifests they may refer to.
+func (c *lintContext) extensionResources() ([]extensionResource, []renderedManifest, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result []extensionResource
+	for _, m := range r.manifests {
+		apiVersion, _ := m.object["apiVersion"].(string)
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil || gv.Group != extensionsGroup {
+			continue
+		}
+		result = append(result, extensionResource{renderedManifest: m, kind: m.resource.Kind})
+	}
+	return result, r.manifests, nil
+}
+
+// setFields returns the paths of fields set in the object, empty values are treated as unset.
+func setFields(object map[string]any, paths ...string) []string {
+	var result []string
+	for _, p := range paths {
+		switch v := lookupValue(object, p).(type) {
+		case nil:
+		case string:
+			if v != "" {
+				result = append(result, p)
+			}
+		case map[string]any:
+			if len(v) > 0 {
+				result = append(result, p)
+			}
+		default:
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// exactlyOne returns the problem of a resource that doesn't set exactly one of the fields, or an empty string.
+func exactlyOne(object map[string]any, fields ...string) string {
+	set := setFields(object, fields...)
+	switch len(set) {
+	case 1:
+		return ""
+	case 0:
+		return fmt.Sprintf("must set exactly one of %s, but sets none", strings.Join(fields, ", "))
+	default:
+		return fmt.Sprintf("must set exactly one of %s, but sets %s", strings.Join(fields, ", "), strings.Join(set, " and "))
+	}
+}
+
+// lintExtensionResources reports JSBundles, APIServices and ReverseProxies that don't set exactly one source
+// or upstream, miss required fields, or link to a path not named after the extension.
+func lintExtensionResources(c *lintContext) ([]lint.Finding, error) {
+	resources, _, err := c.extensionResources()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []lint.Finding
+	for _, r := range resources {
+		report := func(format string, args ...any) {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(r.file),
+				Resource: &r.resource,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+		required := func(paths ...string) {
+			for _, p := range paths {
+				if len(setFields(r.object, p)) == 0 {
+					report("%s is required", p)
+				}
+			}
+		}
+
+		switch r.kind {
+		case "JSBundle":
+			if problem := exactlyOne(r.object, jsBundleSources...); problem != "" {
+				report("%s", problem)
+			}
+			for _, ref := range setFields(r.object, "spec.rawFrom.configMapKeyRef", "spec.rawFrom.secretKeyRef") {
+				required(ref+".name", ref+".key")
+			}
+			link, _ := lookupValue(r.object, "status.link").(string)
+			want := jsBundleLinkPrefix + c.metadata.Name
+			switch {
+			case link == "":
+				report("status.link is required, the console loads the script from it")
+			case !linkMatchesExtension(link, c.metadata.Name):
+				report("status.link %q doesn't match the extension name, it must be under %s or %s-*", link, want, want)
+			}
+		case "APIService":
+			required("spec.group", "spec.version")
+			if problem := exactlyOne(r.object, "spec.url", "spec.service"); problem != "" {
+				report("%s", problem)
+			}
+		case "ReverseProxy":
+			required("spec.matcher.path")
+			if problem := exactlyOne(r.object, "spec.upstream.url", "spec.upstream.service"); problem != "" {
+				report("%s", problem)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// linkMatchesExtension reports whether the JSBundle link is under a directory named after the extension,
+// such as /dist/<name>/index.js or /dist/<name>-frontend/index.js.
+func linkMatchesExtension(link, name string) bool {
+	rest, ok := strings.CutPrefix(path.Clean(link), jsBundleLinkPrefix)
+	if !ok {
+		return false
+	}
+	dir, _, _ := strings.Cut(rest, "/")
+	return dir == name || strings.HasPrefix(dir, name+"-")
+}
+
+// resourceReference is a reference from an extension resource to a resource in the release namespace.
+type resourceReference struct {
+	// field is the path of the reference in the extension resource
+	field string
+	kind  string
+	name  string
+	// key is the key of a ConfigMap or Secret
+	key string
+	// port is the port of a Service, 0 means any
+	port int64
+}
+
+// references returns the ConfigMaps, Secrets and Services an extension resource refers to in the release namespace,
+// those in other namespaces are not part of the chart.
+func (c *lintContext) references(r extensionResource) []resourceReference {
+	namespace := c.releaseNamespace()
+	inRelease := func(ref map[string]any) bool {
+		ns, _ := ref["namespace"].(string)
+		return ns == "" || ns == namespace
+	}
+
+	var result []resourceReference
+	keyRef := func(field, kind string) {
+		ref, ok := lookupValue(r.object, field).(map[string]any)
+		if !ok || !inRelease(ref) {
+			return
+		}
+		name, _ := ref["name"].(string)
+		key, _ := ref["key"].(string)
+		if name != "" {
+			result = append(result, resourceReference{field: field, kind: kind, name: name, key: key})
+		}
+	}
+	service := func(field string) {
+		ref, ok := lookupValue(r.object, field).(map[string]any)
+		if !ok || !inRelease(ref) {
+			return
+		}
+		name, _ := ref["name"].(string)
+		port, _ := toInt64(ref["port"])
+		if name != "" {
+			result = append(result, resourceReference{field: field, kind: "Service", name: name, port: port})
+		}
+	}
+	serviceURL := func(field string) {
+		raw, _ := lookupValue(r.object, field).(string)
+		if ref, ok := serviceURLReference(raw, namespace); ok {
+			ref.field = field
+			result = append(result, ref)
+		}
+	}
+
+	switch r.kind {
+	case "JSBundle":
+		keyRef("spec.rawFrom.configMapKeyRef", "ConfigMap")
+		keyRef("spec.rawFrom.secretKeyRef", "Secret")
+		service("spec.rawFrom.service")
+		serviceURL("spec.rawFrom.url")
+	case "APIService":
+		service("spec.service")
+		serviceURL("spec.url")
+	case "ReverseProxy":
+		service("spec.upstream.service")
+		serviceURL("spec.upstream.url")
+	}
+	return result
+}
+
+// serviceURLReference returns the Service a URL refers to by its cluster DNS name in the namespace,
+// such as http://<name>.<namespace>.svc or http://<name>.<namespace>.svc.cluster.local:8080.
+func serviceURLReference(raw, namespace string) (resourceReference, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return resourceReference{}, false
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".cluster.local")
+	parts := strings.Split(host, ".")
+	if len(parts) != 3 || parts[2] != "svc" || parts[1] != namespace {
+		return resourceReference{}, false
+	}
+
+	ref := resourceReference{kind: "Service", name: parts[0]}
+	switch {
+	case u.Port() != "":
+		ref.port, _ = strconv.ParseInt(u.Port(), 10, 64)
+	case u.Scheme == "http":
+		ref.port = 80
+	case u.Scheme == "https":
+		ref.port = 443
+	}
+	return ref, true
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// lintExtensionResourceReferences reports the ConfigMaps, Secrets and Services in the release namespace
+// that extension resources refer to but the chart doesn't render, and the keys and ports missing from them.
+func lintExtensionResourceReferences(c *lintContext) ([]lint.Finding, error) {
+	resources, objects, err := c.extensionResources()
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := c.releaseNamespace()
+	rendered := make(map[lint.Resource]map[string]any)
+	for _, m := range objects {
+		ns, _ := lookupValue(m.object, "metadata.namespace").(string)
+		if ns == "" || ns == namespace {
+			rendered[lint.Resource{Kind: m.resource.Kind, Name: m.resource.Name}] = m.object
+		}
+	}
+
+	var findings []lint.Finding
+	for _, r := range resources {
+		for _, ref := range c.references(r) {
+			report := func(format string, args ...any) {
+				findings = append(findings, lint.Finding{
+					File:     renderedFilePath(r.file),
+					Resource: &r.resource,
+					Message:  fmt.Sprintf("%s: %s", ref.field, fmt.Sprintf(format, args...)),
+				})
+			}
+
+			object, ok := rendered[lint.Resource{Kind: ref.kind, Name: ref.name}]
+			if !ok {
+				report("%s %s/%s is not rendered by the chart", ref.kind, namespace, ref.name)
+				continue
+			}
+			switch ref.kind {
+			case "ConfigMap", "Secret":
+				if ref.key != "" && !hasKey(object, ref.key, "data", "binaryData", "stringData") {
+					report("%s %s/%s has no key %s", ref.kind, namespace, ref.name, ref.key)
+				}
+			case "Service":
+				if ref.port != 0 && !hasServicePort(object, ref.port) {
+					report("Service %s/%s has no port %d", namespace, ref.name, ref.port)
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+func hasKey(object map[string]any, key string, fields ...string) bool {
+	for _, f := range fields {
+		if data, ok := object[f].(map[string]any); ok {
+			if _, ok := data[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasServicePort(object map[string]any, port int64) bool {
+	for _, p := range lookupPath(object, "spec.ports.port") {
+		if n, ok := toInt64(p); ok && n == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package extension
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// ignoreAnnotation lists the IDs of the rules whose findings on the annotated resource are suppressed,
+// separated by commas, e.g. lint.ksbuilder.kubesphere.io/ignore: security-run-as-root,security-probes
+// The ID helm suppresses the findings of the helm linter on the template the resource is rendered from.
+const ignoreAnnotation = "lint.ksbuilder.kubesphere.io/ignore"
+
+// ignoreDirective matches the comments in templates that suppress rules like ignoreAnnotation,
+// on the resource of the YAML document they are in, e.g. # lint.ksbuilder.kubesphere.io/ignore: global-tolerations
+var ignoreDirective = regexp.MustCompile(`(?m)^\s*#\s*` + regexp.QuoteMeta(ignoreAnnotation) + `:(.*)$`)
+
+// suppressions are the rules ignored on the rendered resources, with how they are ignored for the report.
+type suppressions struct {
+	// resources maps resourceKey to the ignored rule IDs and how each of them is ignored
+	resources map[string]map[string]string
+	// files maps the rendered files to how the helm findings on them are ignored
+	files map[string]string
+}
+
+func newSuppressions() *suppressions {
+	return &suppressions{
+		resources: make(map[string]map[string]string),
+		files:     make(map[string]string),
+	}
+}
+
+// add adds the rules the rendered resources ignore by the annotation and the comment directive.
+func (s *suppressions) add(objects []renderedManifest) {
+	for _, m := range objects {
+		file := renderedFilePath(m.file)
+		ignore := func(value, by string) {
+			for _, id := range strings.Split(value, ",") {
+				if id = strings.TrimSpace(id); id == "" {
+					continue
+				}
+				key := resourceKey(file, m.resource)
+				if s.resources[key] == nil {
+					s.resources[key] = make(map[string]string)
+				}
+				if _, ok := s.resources[key][id]; !ok {
+					s.resources[key][id] = by
+				}
+				if _, ok := s.files[file]; !ok && id == lint.SourceHelm {
+					s.files[file] = by
+				}
+			}
+		}
+
+		annotations, _ := lookupValue(m.object, "metadata.annotations").(map[string]any)
+		if value, ok := annotations[ignoreAnnotation].(string); ok {
+			ignore(value, fmt.Sprintf("the %s annotation of %s", ignoreAnnotation, &m.resource))
+		}
+		for _, match := range ignoreDirective.FindAllStringSubmatch(m.source, -1) {
+			ignore(match[1], fmt.Sprintf("an ignore comment of %s", &m.resource))
+		}
+	}
+}
+
+// suppression returns how the finding of the extension of the tree is ignored, or an empty string when it isn't.
+// Builtin findings are ignored on their resource, and helm findings on their template, whose path is
+// relative to the chart of the finding rather than to the extension directory root.
+func (s *suppressions) suppression(f lint.Finding, t *Tree) string {
+	if f.Source == lint.SourceHelm {
+		c, ok := t.chartAt(f.Chart)
+		if !ok || f.File == "" {
+			return ""
+		}
+		return s.files[path.Join(c.rendered, filepath.ToSlash(f.File))]
+	}
+	// the findings of other extensions
+	if f.Chart != t.path {
+		return ""
+	}
+	if f.Resource == nil {
+		return ""
+	}
+	return s.resources[resourceKey(f.File, *f.Resource)][f.RuleID]
+}
+
+// resourceKey identifies a rendered resource by the path of its template and its kind, namespace and name.
+func resourceKey(file string, r lint.Resource) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", file, r.Kind, r.Namespace, r.Name)
+}
@@ -0,0 +1,148 @@
+package extension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+func TestIgnoreDirective(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// want are the values of the directives in the source, nil when there is none
+		want []string
+	}{
+		{
+			name:   "a comment",
+			source: "# lint.ksbuilder.kubesphere.io/ignore: global-tolerations\nkind: Pod\n",
+			want:   []string{" global-tolerations"},
+		},
+		{
+			name:   "an indented comment without spaces",
+			source: "kind: Pod\nspec:\n    #lint.ksbuilder.kubesphere.io/ignore:helm,global-affinity\n",
+			want:   []string{"helm,global-affinity"},
+		},
+		{
+			name:   "comments on several lines",
+			source: "# lint.ksbuilder.kubesphere.io/ignore: helm\n# lint.ksbuilder.kubesphere.io/ignore: images\nkind: Pod\n",
+			want:   []string{" helm", " images"},
+		},
+		{
+			name:   "the annotation is not a comment",
+			source: "metadata:\n  annotations:\n    lint.ksbuilder.kubesphere.io/ignore: helm\n",
+		},
+		{
+			name:   "a comment after a value",
+			source: "kind: Pod # lint.ksbuilder.kubesphere.io/ignore: helm\n",
+		},
+		{
+			name:   "another annotation",
+			source: "# lint.ksbuilder.kubesphere.io/ignored: helm\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, match := range ignoreDirective.FindAllStringSubmatch(tt.source, -1) {
+				got = append(got, match[1])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("directives = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("directives = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSuppression(t *testing.T) {
+	tree := &Tree{
+		path: "ext",
+		charts: []treeChart{
+			{path: "ext"},
+			{path: filepath.Join("ext", "charts", "backend"), rendered: "charts/backend"},
+			{path: filepath.Join("ext", "charts", "frontend-0.1.0.tgz"), rendered: "charts/frontend"},
+		},
+	}
+	backend := lint.Resource{Kind: "Deployment", Name: "backend"}
+	frontend := lint.Resource{Kind: "Deployment", Name: "frontend"}
+	suppressed := newSuppressions()
+	suppressed.add([]renderedManifest{
+		{
+			file:     "ext/charts/backend/templates/deployment.yaml",
+			resource: backend,
+			object: map[string]any{"metadata": map[string]any{
+				"annotations": map[string]any{ignoreAnnotation: "global-tolerations, helm"},
+			}},
+		},
+		{
+			file:     "ext/charts/frontend/templates/deployment.yaml",
+			resource: frontend,
+			object:   map[string]any{},
+			source:   "# " + ignoreAnnotation + ": helm,,images\nkind: Deployment\n",
+		},
+	})
+
+	tests := []struct {
+		name    string
+		finding lint.Finding
+		want    string
+	}{
+		{
+			name:    "a rule in the annotation",
+			finding: lint.Finding{RuleID: "global-tolerations", Chart: "ext", File: "charts/backend/templates/deployment.yaml", Resource: &backend},
+			want:    "the lint.ksbuilder.kubesphere.io/ignore annotation of Deployment/backend",
+		},
+		{
+			name:    "a rule in the comment",
+			finding: lint.Finding{RuleID: "images", Chart: "ext", File: "charts/frontend/templates/deployment.yaml", Resource: &frontend},
+			want:    "an ignore comment of Deployment/frontend",
+		},
+		{
+			name:    "a rule not ignored",
+			finding: lint.Finding{RuleID: "global-affinity", Chart: "ext", File: "charts/backend/templates/deployment.yaml", Resource: &backend},
+		},
+		{
+			name:    "a rule ignored on another resource",
+			finding: lint.Finding{RuleID: "images", Chart: "ext", File: "charts/backend/templates/deployment.yaml", Resource: &backend},
+		},
+		{
+			name:    "a finding without resource",
+			finding: lint.Finding{RuleID: "images", Chart: "ext", File: "charts/frontend/templates/deployment.yaml"},
+		},
+		{
+			name:    "a finding of another extension",
+			finding: lint.Finding{RuleID: "images", Chart: "other", File: "charts/frontend/templates/deployment.yaml", Resource: &frontend},
+		},
+		{
+			name:    "a helm finding of a subchart",
+			finding: lint.Finding{RuleID: lint.SourceHelm, Source: lint.SourceHelm, Chart: filepath.Join("ext", "charts", "backend"), File: "templates/deployment.yaml"},
+			want:    "the lint.ksbuilder.kubesphere.io/ignore annotation of Deployment/backend",
+		},
+		{
+			name:    "a helm finding of a packaged subchart",
+			finding: lint.Finding{RuleID: lint.SourceHelm, Source: lint.SourceHelm, Chart: filepath.Join("ext", "charts", "frontend-0.1.0.tgz"), File: "templates/deployment.yaml"},
+			want:    "an ignore comment of Deployment/frontend",
+		},
+		{
+			name:    "a helm finding of another template",
+			finding: lint.Finding{RuleID: lint.SourceHelm, Source: lint.SourceHelm, Chart: filepath.Join("ext", "charts", "backend"), File: "templates/service.yaml"},
+		},
+		{
+			name:    "a helm finding of the chart",
+			finding: lint.Finding{RuleID: lint.SourceHelm, Source: lint.SourceHelm, Chart: "ext"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suppressed.suppression(tt.finding, tree); got != tt.want {
+				t.Errorf("suppression = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package extension
+
+import (
+	"fmt"
+
+	"github.com/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// normalizeImage parses an image reference and returns its fully qualified form, so that references
+// to the same image compare equal, e.g. nginx, docker.io/nginx:latest and docker.io/library/nginx:latest.
+// A reference with a digest is identified by the digest alone, as the tag doesn't change the image it pulls.
+func normalizeImage(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		canonical, err := reference.WithDigest(reference.TrimNamed(named), digested.Digest())
+		if err != nil {
+			return "", err
+		}
+		return canonical.String(), nil
+	}
+	return reference.TagNameOnly(named).String(), nil
+}
+
+// containerImages returns the images of all containers in the pod spec.
+func containerImages(spec *corev1.PodSpec) []string {
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.EphemeralContainers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// lintExtensionsImages compares the images declared in extension.yaml with the images of the rendered workloads,
+// and reports the declared images that are never used and the used images that are not declared.
+func lintExtensionsImages(c *lintContext) ([]lint.Finding, error) {
+	var findings []lint.Finding
+	if len(c.metadata.Images) == 0 {
+		findings = append(findings, lint.Finding{
+			File:    api.MetadataFilename,
+			Message: fmt.Sprintf("extension %s has no images", c.metadata.Name),
+		})
+	}
+
+	declared := make(map[string]bool, len(c.metadata.Images))
+	for _, image := range c.metadata.Images {
+		normalized, err := normalizeImage(image)
+		if err != nil {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("image %s is invalid: %v", image, err),
+			})
+			continue
+		}
+		declared[normalized] = true
+	}
+
+	r, err := c.rendered()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, podSpec := range r.podSpecs {
+		for _, image := range containerImages(podSpec.spec) {
+			normalized, err := normalizeImage(image)
+			if err != nil {
+				findings = append(findings, lint.Finding{
+					File:     renderedFilePath(podSpec.file),
+					Resource: &podSpec.resource,
+					Message:  fmt.Sprintf("image %q is invalid: %v", image, err),
+				})
+				continue
+			}
+			if used[normalized] {
+				continue
+			}
+			used[normalized] = true
+			if !declared[normalized] {
+				findings = append(findings, lint.Finding{
+					File:     renderedFilePath(podSpec.file),
+					Resource: &podSpec.resource,
+					Message:  fmt.Sprintf("image %s is used but not declared in the images of %s", image, api.MetadataFilename),
+				})
+			}
+		}
+	}
+
+	for _, image := range c.metadata.Images {
+		if normalized, err := normalizeImage(image); err == nil && !used[normalized] {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("image %s is declared but not used by the chart", image),
+			})
+		}
+	}
+	return findings, nil
+}
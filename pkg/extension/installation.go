@@ -0,0 +1,71 @@
+package extension
+
+import (
+	"fmt"
+	"slices"
+
+	"helm.sh/helm/v3/pkg/chart"
+	corev1alpha1 "kubesphere.io/api/core/v1alpha1"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+const (
+	// tagExtension marks the subcharts deployed to the host cluster in Multicluster mode.
+	tagExtension = "extension"
+	// tagAgent marks the subcharts deployed to the member clusters in Multicluster mode.
+	tagAgent = "agent"
+)
+
+// lintInstallationModeTags reports the dependencies of a Multicluster extension that don't carry exactly one of
+// the extension and agent tags, or don't refer to a subchart of the extension.
+func lintInstallationModeTags(c *lintContext) ([]lint.Finding, error) {
+	if c.metadata.InstallationMode != corev1alpha1.InstallationMulticluster {
+		return nil, nil
+	}
+
+	var findings []lint.Finding
+	report := func(format string, args ...any) {
+		findings = append(findings, lint.Finding{
+			File:    api.MetadataFilename,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	for _, d := range c.metadata.Dependencies {
+		hasExtension, hasAgent := slices.Contains(d.Tags, tagExtension), slices.Contains(d.Tags, tagAgent)
+		switch {
+		case hasExtension && hasAgent:
+			report("dependency %s has both the %s and %s tags, it must have exactly one of them in %s mode",
+				d.Name, tagExtension, tagAgent, corev1alpha1.InstallationMulticluster)
+		case !hasExtension && !hasAgent:
+			report("dependency %s has neither the %s nor the %s tag, it must have exactly one of them in %s mode",
+				d.Name, tagExtension, tagAgent, corev1alpha1.InstallationMulticluster)
+		}
+		if !slices.ContainsFunc(c.chart.Dependencies(), func(ch *chart.Chart) bool {
+			return ch.Name() == d.Name
+		}) {
+			report("dependency %s is not found in the charts directory", d.Name)
+		}
+	}
+	return findings, nil
+}
+
+// lintInstallationModeAgents reports the agent subcharts of a HostOnly extension, which are never deployed.
+func lintInstallationModeAgents(c *lintContext) ([]lint.Finding, error) {
+	if c.metadata.InstallationMode != corev1alpha1.InstallationModeHostOnly {
+		return nil, nil
+	}
+
+	var findings []lint.Finding
+	for _, d := range c.metadata.Dependencies {
+		if slices.Contains(d.Tags, tagAgent) {
+			findings = append(findings, lint.Finding{
+				File: api.MetadataFilename,
+				Message: fmt.Sprintf("dependency %s has the %s tag, but agent subcharts are never deployed in %s mode",
+					d.Name, tagAgent, corev1alpha1.InstallationModeHostOnly),
+			})
+		}
+	}
+	return findings, nil
+}
@@ -1,395 +1,888 @@
 package extension
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"maps"
+	"slices"
+	"sort"
 	"strings"
 
-	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/lint/support"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/kubesphere/ksbuilder/cmd/options"
 	"github.com/kubesphere/ksbuilder/pkg/api"
 	"github.com/kubesphere/ksbuilder/pkg/helm"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
 )
 
-func WithHelm(o *options.LintOptions, paths []string) error {
-	fmt.Print("\n#################### lint by helm ####################\n")
-	if o.Client.WithSubcharts {
-		for _, p := range paths {
-			if err := filepath.Walk(filepath.Join(p, "charts"), func(path string, info os.FileInfo, err error) error {
-				if info != nil {
-					if info.Name() == "Chart.yaml" {
-						paths = append(paths, filepath.Dir(path))
-					} else if strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".tar.gz") {
-						tempDir, err := os.MkdirTemp("", "helm-lint")
-						if err != nil {
-							return err
-						}
-						file, err := os.Open(path)
-						if err != nil {
-							return err
-						}
-						defer func(file *os.File) {
-							_ = file.Close()
-						}(file)
-
-						if err = chartutil.Expand(tempDir, file); err != nil {
-							return err
-						}
-						files, err := os.ReadDir(tempDir)
-						if err != nil {
-							return err
-						}
-						if !files[0].IsDir() {
-							return fmt.Errorf("unexpected file %s in temporary output directory %s", files[0].Name(), tempDir)
-						}
-						paths = append(paths, filepath.Join(tempDir, files[0].Name()))
-						if err := filepath.Walk(filepath.Join(tempDir, files[0].Name(), "charts"), func(path string, info os.FileInfo, err error) error {
-							if info != nil {
-								if info.Name() == "Chart.yaml" {
-									paths = append(paths, filepath.Dir(path))
-								}
-							}
-							return nil
-						}); err != nil {
-							return err
-						}
-					}
-				}
-				return nil
-			}); err != nil {
-				return err
-			}
-		}
-	}
-
+// WithHelm runs the helm chart linter against the charts of the trees and adds its messages to result.
+// The returned error only reports charts that could not be linted, lint failures are recorded in result.
+func WithHelm(o *options.LintOptions, trees []*Tree, result *lint.Result) error {
 	o.Client.Namespace = o.Settings.Namespace()
 	vals, err := o.ValueOpts.MergeValues(getter.All(o.Settings))
 	if err != nil {
 		return err
 	}
+	// the templates are rendered for the target Kubernetes version as well as by the builtin rules
+	if o.TargetKubeVersion != "" {
+		capabilities, err := targetCapabilities(o)
+		if err != nil {
+			return err
+		}
+		o.Client.KubeVersion = &capabilities.KubeVersion
+	}
 
-	var message strings.Builder
-	failed := 0
-	errorsOrWarnings := 0
-
-	for _, path := range paths {
-		var chartmd *chart.Metadata
-		if _, err := os.Stat(path + "/" + "Chart.yaml"); os.IsNotExist(err) {
-			metadata, err := api.LoadMetadata(path)
-			if err != nil {
-				return err
+	for _, t := range trees {
+		for _, c := range t.charts {
+			lintResult := helm.Lint(o.Client, c.dir, vals, c.metadata)
+			result.AddChart(c.path)
+
+			// All the Errors that are generated by a chart
+			// that failed a lint will be included in the
+			// results.Messages so we only need to report
+			// the Errors if there are no Messages.
+			if len(lintResult.Messages) == 0 {
+				for _, err := range lintResult.Errors {
+					result.Add(lint.Finding{
+						RuleID:   lint.SourceHelm,
+						Severity: lint.SeverityError,
+						Source:   lint.SourceHelm,
+						Chart:    c.path,
+						Message:  err.Error(),
+					})
+				}
 			}
-			chartmd = metadata.ToChartYaml()
 
-		} else {
-			chartmd, err = chartutil.LoadChartfile(path + "/" + "Chart.yaml")
-			if err != nil {
-				return err
+			for _, msg := range lintResult.Messages {
+				result.Add(helmFinding(c.path, msg))
 			}
 		}
+	}
+	return nil
+}
 
-		result := helm.Lint(o.Client, path, vals, chartmd)
+// helmFinding converts a message of the helm linter to a lint finding.
+func helmFinding(chartPath string, msg support.Message) lint.Finding {
+	severity := lint.SeverityInfo
+	switch msg.Severity {
+	case support.ErrorSev:
+		severity = lint.SeverityError
+	case support.WarningSev:
+		severity = lint.SeverityWarning
+	}
+	return lint.Finding{
+		RuleID:   lint.SourceHelm,
+		Severity: severity,
+		Source:   lint.SourceHelm,
+		Chart:    chartPath,
+		File:     msg.Path,
+		Message:  msg.Err.Error(),
+	}
+}
 
-		// If there is no errors/warnings and quiet flag is set
-		// go to the next chart
-		hasWarningsOrErrors := action.HasWarningsOrErrors(result)
-		if hasWarningsOrErrors {
-			errorsOrWarnings++
-		}
-		if o.Client.Quiet && !hasWarningsOrErrors {
-			continue
-		}
+// lintContext is the extension the builtin rules run against.
+type lintContext struct {
+	options  *options.LintOptions
+	metadata *api.Metadata
+	chart    *chart.Chart
+	// dir is the extension directory, which the local files extension.yaml refers to are relative to
+	dir string
+	// capabilities are the capabilities of --target-kube-version, or the helm defaults
+	capabilities *chartutil.Capabilities
+	// podTemplates are the pod template paths of custom kinds, see lint.Config
+	podTemplates map[string][]string
+	// values are the combination of the values matrix the chart is rendered with
+	values lint.Combination
+	// rendering and probe are the chart rendered with values, and with the global values set to probeKeys as well,
+	// which are rendered once for all rules of the combination
+	rendering *rendering
+	probe     *rendering
+	probeKeys map[string]string
+	// schemaSet is the bundled schemas and those of --schemas, see schemas
+	schemaSet schemaSet
+}
 
-		fmt.Fprintf(&message, "==> Linting %s\n", path)
+// renderFailure is the rule ID of the charts that can't be rendered when the rendered-manifests rule is disabled.
+const renderFailure = "render"
 
-		// All the Errors that are generated by a chart
-		// that failed a lint will be included in the
-		// results.Messages so we only need to print
-		// the Errors if there are no Messages.
-		if len(result.Messages) == 0 {
-			for _, err := range result.Errors {
-				fmt.Fprintf(&message, "Error %s\n", err)
-			}
+// renderError is an error rendering the chart with the values of the lint run.
+type renderError struct {
+	err error
+}
+
+func (e *renderError) Error() string {
+	return fmt.Sprintf("unable to render the chart: %v", e.err)
+}
+
+func (e *renderError) Unwrap() error {
+	return e.err
+}
+
+// render renders the chart with the values of the lint options, setValues can add values for a single rule
+// without affecting the renders of other rules.
+func (c *lintContext) render(setValues func(*values.Options)) (map[string]string, error) {
+	o := *c.options
+	valueOpts := *o.ValueOpts
+	valueOpts.ValueFiles = slices.Clone(valueOpts.ValueFiles)
+	valueOpts.Values = slices.Clone(valueOpts.Values)
+	valueOpts.StringValues = slices.Clone(valueOpts.StringValues)
+	valueOpts.FileValues = slices.Clone(valueOpts.FileValues)
+	valueOpts.JSONValues = slices.Clone(valueOpts.JSONValues)
+	valueOpts.LiteralValues = slices.Clone(valueOpts.LiteralValues)
+	valueOpts.JSONValues = append(valueOpts.JSONValues, c.values...)
+	if setValues != nil {
+		setValues(&valueOpts)
+	}
+	o.ValueOpts = &valueOpts
+
+	files, err := getTemplateFile(&o, copyChart(c.chart), c.capabilities, c.metadata.Name, c.releaseNamespace())
+	if err != nil {
+		return nil, &renderError{err: err}
+	}
+	return files, nil
+}
+
+// rendered returns the chart rendered with the values of the lint run, the rules share it.
+func (c *lintContext) rendered() (*rendering, error) {
+	if c.rendering == nil {
+		files, err := c.render(nil)
+		if err != nil {
+			return nil, err
 		}
+		c.rendering = c.newRendering(files)
+	}
+	return c.rendering, nil
+}
 
-		for _, msg := range result.Messages {
-			if !o.Client.Quiet || msg.Severity > support.InfoSev {
-				fmt.Fprintf(&message, "%s\n", msg)
+// probed returns the chart rendered with globalValues set to random keys as well, which the workloads must propagate.
+// All global values are probed in a single render, the key of each one is in probeKeys.
+func (c *lintContext) probed() (*rendering, error) {
+	if c.probe == nil {
+		c.probeKeys = make(map[string]string, len(globalValues))
+		for _, value := range slices.Sorted(maps.Keys(globalValues)) {
+			c.probeKeys[value] = rand.String(12)
+		}
+		files, err := c.render(func(v *values.Options) {
+			for value, key := range c.probeKeys {
+				globalValues[value](v, key)
+			}
+		})
+		if err != nil {
+			var re *renderError
+			if errors.As(err, &re) {
+				re.err = fmt.Errorf("with %s set: %w", strings.Join(slices.Sorted(maps.Keys(globalValues)), ", "), re.err)
 			}
+			return nil, err
 		}
+		c.probe = c.newRendering(files)
+	}
+	return c.probe, nil
+}
 
-		if len(result.Errors) != 0 {
-			failed++
-		}
+// releaseNamespace returns the namespace KubeSphere installs the extension into, the release is named after the extension.
+func (c *lintContext) releaseNamespace() string {
+	if c.metadata.Namespace != "" {
+		return c.metadata.Namespace
+	}
+	return fmt.Sprintf("extension-%s", c.metadata.Name)
+}
 
-		// Adding extra new line here to break up the
-		// results, stops this from being a big wall of
-		// text and makes it easier to follow.
-		fmt.Fprint(&message, "\n")
+// copyChart copies the chart with its subcharts and their dependency metadata, which rendering modifies
+// to disable subcharts by their tags and conditions, so that every render starts from the loaded chart.
+func copyChart(c *chart.Chart) *chart.Chart {
+	ch := *c
+	if c.Metadata != nil {
+		metadata := *c.Metadata
+		metadata.Dependencies = make([]*chart.Dependency, 0, len(c.Metadata.Dependencies))
+		for _, d := range c.Metadata.Dependencies {
+			dependency := *d
+			metadata.Dependencies = append(metadata.Dependencies, &dependency)
+		}
+		ch.Metadata = &metadata
+	}
+	dependencies := make([]*chart.Chart, 0, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		dependencies = append(dependencies, copyChart(d))
 	}
+	ch.SetDependencies(dependencies...)
+	return &ch
+}
 
-	fmt.Print(message.String())
+// builtinRule is a KubeSphere extension lint rule, its check reports findings without rule ID and severity.
+type builtinRule struct {
+	lint.Rule
+	check func(c *lintContext) ([]lint.Finding, error)
+}
 
-	summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
-	if failed > 0 {
-		return fmt.Errorf("error: %s", summary)
+// builtinRules is the registry of builtin rules, in the order they run.
+var builtinRules = []builtinRule{
+	{
+		Rule: lint.Rule{
+			ID:          "extension-name",
+			Description: "The extension name must be a valid DNS-1123 subdomain.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintExtensionsName,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "locales",
+			Description: "Every language of displayName must have a description, a provider, a README and a CHANGELOG.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintLocales,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "static-assets",
+			Description: "The local icon and screenshots must exist in the staticFileDirectory, be of supported types and SVG must not contain scripts or external references.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintStaticAssets,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "static-asset-sizes",
+			Description: "The local icon and screenshots should be within the size limits, and screenshots should have readable dimensions from 1:1 to 4:1.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintStaticAssetSizes,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "external-dependencies",
+			Description: "The external dependencies must have valid names, version constraints and types, and must not include the extension itself.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintExternalDependencies,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "external-dependency-graph",
+			Description: "The external dependencies must be resolvable against the --catalog extensions without cycles.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintExternalDependencyGraph,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "installation-mode-tags",
+			Description: "In Multicluster mode, every dependency must be a subchart with exactly one of the extension and agent tags.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintInstallationModeTags,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "installation-mode-agents",
+			Description: "In HostOnly mode, the extension should not ship agent subcharts, which are never deployed.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintInstallationModeAgents,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "version-constraints",
+			Description: "ksVersion and kubeVersion must be semver constraints that some version can satisfy.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintVersionConstraints,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "target-versions",
+			Description: "The extension must allow the --target-ks-version and --target-kube-version, and must not use APIs removed from the target Kubernetes.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintTargetVersions,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "deprecated-apis",
+			Description: "The chart should not use APIs deprecated in the --target-kube-version.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintDeprecatedAPIs,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "rendered-manifests",
+			Description: "The rendered manifests and the pod templates of workloads must be decodable.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintRenderedManifests,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "schemas",
+			Description: "The rendered manifests must be valid against the schemas of Kubernetes, the bundled KubeSphere CRDs, the CRDs of the chart and those of --schemas.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintSchemas,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "schema-coverage",
+			Description: "The rendered manifests should have a schema to be validated against.",
+			Severity:    lint.SeverityInfo,
+		},
+		check: lintSchemaCoverage,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "extension-resources",
+			Description: "JSBundles, APIServices and ReverseProxies must set exactly one source or upstream, and JSBundles must link to a path named after the extension.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintExtensionResources,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "extension-resource-references",
+			Description: "The ConfigMaps, Secrets and Services that JSBundles, APIServices and ReverseProxies refer to in the release namespace must be rendered by the chart.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintExtensionResourceReferences,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "images",
+			Description: "The images declared in extension.yaml must match the images of the rendered workloads.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintExtensionsImages,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-image-registry",
+			Description: "Every container must pull its image from global.imageRegistry when it is set.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintGlobalImageRegistry,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-node-selector",
+			Description: "Every workload must be scheduled with global.nodeSelector when it is set.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintGlobalNodeSelector,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-tolerations",
+			Description: "Every workload must tolerate global.tolerations when it is set.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintGlobalTolerations,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-affinity",
+			Description: "Every workload must be scheduled with global.affinity when it is set.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintGlobalAffinity,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-image-pull-secrets",
+			Description: "Every workload must pull images with global.imagePullSecrets when it is set.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintGlobalImagePullSecrets,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "global-priority-class-name",
+			Description: "Every workload must run with global.priorityClassName when it is set.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintGlobalPriorityClassName,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "permissions",
+			Description: "The roles in permissions.yaml must allow to create every resource of the chart.",
+			Severity:    lint.SeverityError,
+		},
+		check: lintPermissions,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "permissions-wildcard",
+			Description: "The rules in permissions.yaml should not grant '*' verbs or resources.",
+			Severity:    lint.SeverityWarning,
+		},
+		check: lintPermissionsWildcard,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-privileged",
+			Description: "Containers should not be privileged or add the ALL or SYS_ADMIN capabilities.",
+			Severity:    lint.SeverityError,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintPrivileged,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-host-access",
+			Description: "Pods should not use the host network, PID or IPC namespaces, host paths or host ports.",
+			Severity:    lint.SeverityWarning,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintHostAccess,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-resources",
+			Description: "Containers should have cpu and memory requests and limits.",
+			Severity:    lint.SeverityWarning,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintResources,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-image-tags",
+			Description: "Images should be pinned to a tag other than latest or to a digest.",
+			Severity:    lint.SeverityWarning,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintImageTags,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-run-as-root",
+			Description: "Containers should run as a non-root user.",
+			Severity:    lint.SeverityWarning,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintRunAsRoot,
+	},
+	{
+		Rule: lint.Rule{
+			ID:          "security-probes",
+			Description: "The containers of long-running workloads should have readiness and liveness probes.",
+			Severity:    lint.SeverityWarning,
+			OptIn:       true,
+			Pack:        lint.PackSecurity,
+		},
+		check: lintProbes,
+	},
+}
+
+// BuiltinRules returns the builtin KubeSphere extension lint rules with their default severity.
+func BuiltinRules() []lint.Rule {
+	rules := make([]lint.Rule, 0, len(builtinRules))
+	for _, r := range builtinRules {
+		rules = append(rules, r.Rule)
 	}
-	if !o.Client.Quiet || errorsOrWarnings > 0 {
-		fmt.Print(summary)
+	return rules
+}
+
+// WithBuiltins runs the builtin KubeSphere extension rules against the extensions of the trees and adds their findings
+// to result. The rules are configured by the lint configuration file of every extension and then by the
+// --enable/--disable flags, --security enables the rules of the security pack unless either of them disables them.
+// The findings that the rendered resources ignore by ignoreAnnotation or ignoreDirective are moved to the suppressed
+// findings of result, including those WithHelm added before. The trees of plain helm charts are skipped, see
+// Tree.IsExtension.
+func WithBuiltins(o *options.LintOptions, trees []*Tree, result *lint.Result) error {
+	for _, t := range trees {
+		if !t.IsExtension() {
+			continue
+		}
+		if err := lintBuiltins(o, t, result); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func WithBuiltins(o *options.LintOptions, paths []string) error {
-	fmt.Print("\n#################### lint by kubesphere ####################\n")
-	ext, err := Load(paths[0])
+// lintBuiltins runs the builtin rules against the extension of the tree.
+func lintBuiltins(o *options.LintOptions, t *Tree, result *lint.Result) error {
+	config, err := lint.LoadConfig(t.dir)
 	if err != nil {
 		return err
 	}
-	chartRequested, err := helm.Load(paths[0], ext.Metadata.ToChartYaml())
+	// the rules of the pack are enabled before the configuration file and the flags, which may still disable them
+	var pack *lint.Config
+	if o.Security {
+		pack = &lint.Config{Enable: lint.PackRules(BuiltinRules(), lint.PackSecurity)}
+	}
+	rules, err := lint.ResolveRules(BuiltinRules(), pack, config, &lint.Config{Enable: o.EnableRules, Disable: o.DisableRules})
 	if err != nil {
 		return err
 	}
+	// the rules of the extensions linted before take precedence in the report
+	for _, r := range rules {
+		if !slices.ContainsFunc(result.Rules, func(rule lint.Rule) bool { return rule.ID == r.ID }) {
+			result.Rules = append(result.Rules, r)
+		}
+	}
 
-	if err := lintExtensionsName(ext.Metadata.Name); err != nil {
+	capabilities, err := targetCapabilities(o)
+	if err != nil {
 		return err
 	}
-	if err := lintExtensionsImages(*o, *chartRequested, ext.Metadata.Name, ext.Metadata.Images); err != nil {
-		return err
+	c := &lintContext{
+		options:      o,
+		metadata:     t.metadata,
+		chart:        t.chart,
+		dir:          t.dir,
+		capabilities: capabilities,
+		podTemplates: config.PodTemplates,
 	}
-	if err := lintGlobalImageRegistry(*o, *chartRequested, ext.Metadata.Name); err != nil {
-		return err
+
+	combinations := []lint.Combination{nil}
+	if o.ValuesMatrix != "" {
+		matrix, err := lint.LoadValuesMatrix(o.ValuesMatrix)
+		if err != nil {
+			return err
+		}
+		if combinations, err = matrix.Combinations(); err != nil {
+			return err
+		}
 	}
-	if err := lintGlobalNodeSelector(*o, *chartRequested, ext.Metadata.Name); err != nil {
-		return err
+
+	result.AddChart(t.path)
+	// the findings of all combinations, the same finding of several combinations is merged
+	var findings, failures []*lint.Finding
+	merged := make(map[string]*lint.Finding)
+	// the helm linter doesn't render the chart with the values matrix, so its findings are ignored by the templates
+	// of any combination, while the findings of the builtin rules only by the templates of their own combinations
+	helmSuppressed := newSuppressions()
+	for _, combination := range combinations {
+		c.values = combination
+		c.rendering, c.probe = nil, nil
+		// a chart that can't be rendered with the values fails every rule rendering it, so report it once.
+		// It is reported even if the rendered-manifests rule is disabled, since none of the rules has linted the chart.
+		r, err := c.rendered()
+		if err != nil {
+			var re *renderError
+			if !errors.As(err, &re) {
+				return err
+			}
+			failure := &lint.Finding{
+				RuleID:       renderFailure,
+				Severity:     lint.SeverityError,
+				Message:      re.Error(),
+				Combinations: combinationNames(combination),
+			}
+			if i := slices.IndexFunc(rules, func(r lint.Rule) bool { return r.ID == "rendered-manifests" }); i >= 0 {
+				failure.RuleID, failure.Severity = rules[i].ID, rules[i].Severity
+			}
+			failures = append(failures, failure)
+			continue
+		}
+		suppressed := newSuppressions()
+		suppressed.add(r.manifests)
+		helmSuppressed.add(r.manifests)
+
+		for _, rule := range rules {
+			i := slices.IndexFunc(builtinRules, func(r builtinRule) bool {
+				return r.ID == rule.ID
+			})
+			ruleFindings, err := builtinRules[i].check(c)
+			var re *renderError
+			switch {
+			case errors.As(err, &re):
+				// the rule renders the chart with values of its own, which the chart can't be rendered with
+				ruleFindings = []lint.Finding{{Message: re.Error()}}
+			case err != nil:
+				return fmt.Errorf("lint rule %s: %w", rule.ID, err)
+			}
+			for _, f := range ruleFindings {
+				f.RuleID = rule.ID
+				// a check may lower the severity of the findings that are not the problems of the extension itself,
+				// such as those of the --catalog extensions, the others take the severity of the rule
+				if !f.KeepSeverity || f.Severity > rule.Severity {
+					f.Severity = rule.Severity
+				}
+				f.Source, f.Chart = lint.SourceKubeSphere, t.path
+				f.Suppression = suppressed.suppression(f, t)
+				key := fmt.Sprintf("%s\x00%s\x00%v\x00%s\x00%s", f.RuleID, f.File, f.Resource, f.Message, f.Suppression)
+				m, ok := merged[key]
+				if !ok {
+					m = &f
+					merged[key] = m
+					findings = append(findings, m)
+				}
+				if !slices.Contains(m.Combinations, combination.String()) {
+					m.Combinations = append(m.Combinations, combinationNames(combination)...)
+				}
+			}
+		}
 	}
-	return nil
-}
 
-func lintExtensionsName(extension string) error {
-	fmt.Print("\nInfo: lint name\n")
-	if errs := validation.IsDNS1123Subdomain(extension); len(errs) != 0 {
-		fmt.Printf("ERROR: extension name \"%s\" is invalid:\n  error: %s\n", extension, strings.Join(errs, "\n  error: "))
+	for _, f := range findings {
+		// a finding of every combination doesn't depend on the values
+		if len(f.Combinations) == len(combinations) {
+			f.Combinations = nil
+		}
 	}
+	for _, f := range append(findings, failures...) {
+		f.Source = lint.SourceKubeSphere
+		f.Chart = t.path
+		result.Add(*f)
+	}
+	result.Suppress(func(f lint.Finding) string {
+		if f.Source == lint.SourceHelm {
+			return helmSuppressed.suppression(f, t)
+		}
+		// the builtin findings of this extension are ignored by their combinations above
+		return f.Suppression
+	})
 	return nil
 }
 
-func lintExtensionsImages(o options.LintOptions, charts chart.Chart, extension string, images []string) error {
-	fmt.Print("\nInfo: lint images\n")
-	if len(images) == 0 {
-		fmt.Printf("WARNING: extension %s has no images\n", extension)
+// combinationNames returns the combination as the combinations of a finding, nil when there is no values matrix.
+func combinationNames(c lint.Combination) []string {
+	if len(c) == 0 {
 		return nil
 	}
+	return []string{c.String()}
+}
 
-	files, err := getTemplateFile(&o, &charts)
-	if err != nil {
-		return err
-	}
-
-	for _, image := range images {
-		for name, content := range files {
-			// only find in yaml files
-			if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
-				continue
-			}
-			if strings.Contains(content, image) ||
-				(strings.HasPrefix(image, "docker.io/") && strings.Contains(content, strings.TrimPrefix(image, "docker.io/"))) ||
-				(strings.HasPrefix(image, "docker.io/library/") && strings.Contains(content, strings.TrimPrefix(image, "docker.io/library/"))) {
-				goto found
-			}
-		}
-		fmt.Printf("WARNING: image %s has not found\n", image)
-	found:
+func lintExtensionsName(c *lintContext) ([]lint.Finding, error) {
+	var findings []lint.Finding
+	for _, msg := range validation.IsDNS1123Subdomain(c.metadata.Name) {
+		findings = append(findings, lint.Finding{
+			File:    api.MetadataFilename,
+			Message: fmt.Sprintf("extension name %q is invalid: %s", c.metadata.Name, msg),
+		})
 	}
-	return nil
+	return findings, nil
 }
 
-func lintGlobalNodeSelector(o options.LintOptions, charts chart.Chart, extension string) error {
-	fmt.Println("\nInfo: lint global.nodeSelector")
-
-	// Generate a random key for nodeSelector
-	key := rand.String(12)
-	o.ValueOpts.JSONValues = append(o.ValueOpts.JSONValues, fmt.Sprintf("global.nodeSelector={\"kubernetes.io/os\": \"%s\"}", key))
-
-	files, err := getTemplateFile(&o, &charts)
-	if err != nil {
-		return err
+// lintLocales reports the languages of displayName that miss any of the other localized metadata and docs.
+func lintLocales(c *lintContext) ([]lint.Finding, error) {
+	// docs maps the files of the extension to whether they have any content
+	docs := make(map[string]bool, len(c.chart.Files))
+	for _, f := range c.chart.Files {
+		docs[f.Name] = len(strings.TrimSpace(string(f.Data))) > 0
 	}
 
-	// Store errors grouped by file
-	errs := make(map[string][]string)
-
-	// Helper function to check nodeSelector
-	checkNodeSelector := func(spec map[string]any, kind, name, filename string) {
-		if nodeSelector, ok := spec["nodeSelector"].(map[string]any); !ok || nodeSelector["kubernetes.io/os"] != key {
-			errs[filename] = append(errs[filename], fmt.Sprintf(
-				"Resource: {kind: %s, name: %s }", kind, name,
-			))
+	var findings []lint.Finding
+	for _, lang := range slices.Sorted(maps.Keys(c.metadata.DisplayName)) {
+		var missing, empty []string
+		if strings.TrimSpace(string(c.metadata.Description[lang])) == "" {
+			missing = append(missing, "description")
+		}
+		if p := c.metadata.Provider[lang]; p == nil || p.Name == "" {
+			missing = append(missing, "provider")
+		}
+		for _, name := range []string{api.ReadmeFilename(lang), api.ChangelogFilename(lang)} {
+			if hasContent, ok := docs[name]; !ok {
+				missing = append(missing, name)
+			} else if !hasContent {
+				empty = append(empty, name)
+			}
 		}
-	}
 
-	// Process each YAML file
-	for filename, content := range files {
-		if !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") {
-			continue
+		var problems []string
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("missing %s", strings.Join(missing, ", ")))
+		}
+		if len(empty) > 0 {
+			problems = append(problems, fmt.Sprintf("empty %s", strings.Join(empty, ", ")))
 		}
+		if len(problems) > 0 {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("locale %q is incomplete: %s", lang, strings.Join(problems, "; ")),
+			})
+		}
+	}
+	return findings, nil
+}
 
-		for _, m := range releaseutil.SplitManifests(content) {
-			var resource map[string]any
-			if err := yaml.Unmarshal([]byte(m), &resource); err != nil {
-				return fmt.Errorf("fail to decoding YAML file %s .error is: %w", filename, err)
-			}
+// globalValues are the global values KubeSphere sets for all extensions, by how each one is set to a key.
+var globalValues = map[string]func(v *values.Options, key string){
+	"global.nodeSelector": func(v *values.Options, key string) {
+		v.JSONValues = append(v.JSONValues, fmt.Sprintf("global.nodeSelector={\"kubernetes.io/os\": \"%s\"}", key))
+	},
+	"global.tolerations": func(v *values.Options, key string) {
+		v.JSONValues = append(v.JSONValues, fmt.Sprintf("global.tolerations=[{\"key\": \"%s\", \"operator\": \"Exists\", \"effect\": \"NoSchedule\"}]", key))
+	},
+	"global.affinity": func(v *values.Options, key string) {
+		v.JSONValues = append(v.JSONValues, fmt.Sprintf("global.affinity={\"nodeAffinity\": {\"requiredDuringSchedulingIgnoredDuringExecution\": "+
+			"{\"nodeSelectorTerms\": [{\"matchExpressions\": [{\"key\": \"%s\", \"operator\": \"Exists\"}]}]}}}", key))
+	},
+	"global.imagePullSecrets": func(v *values.Options, key string) {
+		v.JSONValues = append(v.JSONValues, fmt.Sprintf("global.imagePullSecrets=[{\"name\": \"%s\"}]", key))
+	},
+	"global.priorityClassName": func(v *values.Options, key string) {
+		v.Values = append(v.Values, fmt.Sprintf("global.priorityClassName=%s", key))
+	},
+	"global.imageRegistry": func(v *values.Options, key string) {
+		v.Values = append(v.Values, fmt.Sprintf("global.imageRegistry=%s", key))
+	},
+}
 
-			// Check nodeSelector for specific kinds
-			switch resource["kind"] {
-			case "Deployment", "StatefulSet", "ReplicaSet", "Job":
-				if spec, ok := resource["spec"].(map[string]any); ok {
-					if template, ok := spec["template"].(map[string]any); ok {
-						if templateSpec, ok := template["spec"].(map[string]any); ok {
-							checkNodeSelector(templateSpec, resource["kind"].(string), resource["metadata"].(map[string]any)["name"].(string), filename)
-						}
-					}
-				}
+func lintGlobalNodeSelector(c *lintContext) ([]lint.Finding, error) {
+	return lintGlobalPodSpecValue(c, "global.nodeSelector",
+		func(spec *corev1.PodSpec, key string) bool {
+			return spec.NodeSelector["kubernetes.io/os"] == key
+		})
+}
 
-			case "Pod":
-				if spec, ok := resource["spec"].(map[string]any); ok {
-					checkNodeSelector(spec, resource["kind"].(string), resource["metadata"].(map[string]any)["name"].(string), filename)
-				}
+func lintGlobalTolerations(c *lintContext) ([]lint.Finding, error) {
+	return lintGlobalPodSpecValue(c, "global.tolerations",
+		func(spec *corev1.PodSpec, key string) bool {
+			return slices.ContainsFunc(spec.Tolerations, func(t corev1.Toleration) bool {
+				return t.Key == key
+			})
+		})
+}
 
-			case "CronJob":
-				if spec, ok := resource["spec"].(map[string]any); ok {
-					if jobTemplate, ok := spec["jobTemplate"].(map[string]any); ok {
-						if jobSpec, ok := jobTemplate["spec"].(map[string]any); ok {
-							if template, ok := jobSpec["template"].(map[string]any); ok {
-								if templateSpec, ok := template["spec"].(map[string]any); ok {
-									checkNodeSelector(templateSpec, resource["kind"].(string), resource["metadata"].(map[string]any)["name"].(string), filename)
-								}
-							}
-						}
+func lintGlobalAffinity(c *lintContext) ([]lint.Finding, error) {
+	return lintGlobalPodSpecValue(c, "global.affinity",
+		func(spec *corev1.PodSpec, key string) bool {
+			if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+				return false
+			}
+			for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+				for _, expression := range term.MatchExpressions {
+					if expression.Key == key {
+						return true
 					}
 				}
 			}
-		}
+			return false
+		})
+}
 
-	}
+func lintGlobalImagePullSecrets(c *lintContext) ([]lint.Finding, error) {
+	return lintGlobalPodSpecValue(c, "global.imagePullSecrets",
+		func(spec *corev1.PodSpec, key string) bool {
+			return slices.ContainsFunc(spec.ImagePullSecrets, func(s corev1.LocalObjectReference) bool {
+				return s.Name == key
+			})
+		})
+}
 
-	// Report errors
-	if len(errs) > 0 {
-		fmt.Printf("ERROR: global.nodeSelector doesn't work in \"%s\"\n", extension)
-		for file, messages := range errs {
-			fmt.Printf("  File \"%s\":\n    %s\n", file, strings.Join(messages, "\n    "))
+func lintGlobalPriorityClassName(c *lintContext) ([]lint.Finding, error) {
+	return lintGlobalPodSpecValue(c, "global.priorityClassName",
+		func(spec *corev1.PodSpec, key string) bool {
+			return spec.PriorityClassName == key
+		})
+}
+
+// lintGlobalPodSpecValue reports the workloads whose pod spec doesn't propagate the key the global value is probed with.
+func lintGlobalPodSpecValue(c *lintContext, value string, propagated func(spec *corev1.PodSpec, key string) bool) ([]lint.Finding, error) {
+	r, err := c.probed()
+	if err != nil {
+		return nil, err
+	}
+	key := c.probeKeys[value]
+
+	var findings []lint.Finding
+	for _, podSpec := range r.podSpecs {
+		if !propagated(podSpec.spec, key) {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(podSpec.file),
+				Resource: &podSpec.resource,
+				Message:  fmt.Sprintf("%s doesn't work", value),
+			})
 		}
 	}
-
-	return nil
+	return findings, nil
 }
 
-func lintGlobalImageRegistry(o options.LintOptions, charts chart.Chart, extension string) error {
-	fmt.Print("\nInfo: lint global.imageRegistry\n")
-
-	// Generate a unique registry key for validation
-	key := rand.String(12)
-	o.ValueOpts.Values = append(o.ValueOpts.Values, fmt.Sprintf("global.imageRegistry=%s", key))
-
-	// Retrieve rendered template files
-	files, err := getTemplateFile(&o, &charts)
+func lintGlobalImageRegistry(c *lintContext) ([]lint.Finding, error) {
+	r, err := c.probed()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	key := c.probeKeys["global.imageRegistry"]
 
-	// Store errors grouped by file
-	errs := make(map[string][]string)
-
-	// Define a function for container validation
-	checkContainers := func(spec map[string]any, kind, name, filename string) {
+	var findings []lint.Finding
+	for _, podSpec := range r.podSpecs {
 		var containers []string
 		var initContainers []string
 
 		// Validate initContainers
-		if initContainerList, ok := spec["initContainers"].([]any); ok {
-			for _, c := range initContainerList {
-				container := c.(map[string]any)
-				if !strings.Contains(container["image"].(string), key) {
-					initContainers = append(initContainers, container["name"].(string))
-				}
+		for _, container := range podSpec.spec.InitContainers {
+			if !strings.Contains(container.Image, key) {
+				initContainers = append(initContainers, container.Name)
 			}
 		}
 
 		// Validate containers
-		if containerList, ok := spec["containers"].([]any); ok {
-			for _, c := range containerList {
-				container := c.(map[string]any)
-				if !strings.Contains(container["image"].(string), key) {
-					containers = append(containers, container["name"].(string))
-				}
+		for _, container := range podSpec.spec.Containers {
+			if !strings.Contains(container.Image, key) {
+				containers = append(containers, container.Name)
 			}
 		}
 
-		if len(containers) > 0 || len(initContainers) > 0 {
-			errs[filename] = append(errs[filename], fmt.Sprintf(
-				"Resource: {kind: %s, name: %s } InitContainer: [ %s ] Container: [ %s ]", kind, name,
-				strings.Join(initContainers, ", "),
-				strings.Join(containers, ", "),
-			))
+		var parts []string
+		if len(initContainers) > 0 {
+			parts = append(parts, fmt.Sprintf("initContainers [%s]", strings.Join(initContainers, ", ")))
 		}
-	}
-
-	// Iterate over all files
-	for filename, content := range files {
-		// Skip non-YAML files
-		if !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") {
-			continue
+		if len(containers) > 0 {
+			parts = append(parts, fmt.Sprintf("containers [%s]", strings.Join(containers, ", ")))
 		}
+		if len(parts) > 0 {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(podSpec.file),
+				Resource: &podSpec.resource,
+				Message:  fmt.Sprintf("global.imageRegistry doesn't work for %s", strings.Join(parts, " and ")),
+			})
+		}
+	}
 
-		for _, m := range releaseutil.SplitManifests(content) {
-			var resource map[string]any
-			if err := yaml.Unmarshal([]byte(m), &resource); err != nil {
-				return fmt.Errorf("fail to decoding YAML file %s .error is: %w", filename, err)
-			}
+	return findings, nil
+}
 
-			// Check resources based on their kind
-			// var containers, initContainers []string
-			switch resource["kind"] {
-			case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
-				checkContainers(resource["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any),
-					resource["kind"].(string),
-					resource["metadata"].(map[string]any)["name"].(string), filename)
-
-			case "Pod":
-				checkContainers(resource["spec"].(map[string]any),
-					resource["kind"].(string),
-					resource["metadata"].(map[string]any)["name"].(string), filename)
-
-			case "CronJob":
-				checkContainers(resource["spec"].(map[string]any)["jobTemplate"].(map[string]any)["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any), resource["kind"].(string),
-					resource["metadata"].(map[string]any)["name"].(string), filename)
-			}
-		}
+// lintRenderedManifests reports the rendered documents and pod templates that can't be decoded.
+func lintRenderedManifests(c *lintContext) ([]lint.Finding, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, err
 	}
+	return r.malformed, nil
+}
 
-	// Report errors
-	if len(errs) > 0 {
-		fmt.Printf("ERROR: global.imageRegistry doesn't work in \"%s\"\n", extension)
-		for file, messages := range errs {
-			fmt.Printf("  File \"%s\":\n    %s\n", file, strings.Join(messages, "\n    "))
-		}
+// splitManifests splits a rendered file into its YAML documents, keeping their order in the file.
+func splitManifests(content string) []string {
+	manifests := releaseutil.SplitManifests(content)
+	keys := slices.Collect(maps.Keys(manifests))
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	result := make([]string, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, manifests[k])
 	}
+	return result
+}
 
-	return nil
+// renderedFilePath converts the name of a rendered template, which starts with the chart name,
+// to a path relative to the extension directory.
+// e.g. extension/charts/frontend/templates/deployment.yaml -> charts/frontend/templates/deployment.yaml
+func renderedFilePath(name string) string {
+	if _, p, ok := strings.Cut(name, "/"); ok {
+		return p
+	}
+	return name
 }
 
-func getTemplateFile(o *options.LintOptions, chartRequested *chart.Chart) (map[string]string, error) {
+func getTemplateFile(o *options.LintOptions, chartRequested *chart.Chart, capabilities *chartutil.Capabilities, releaseName, namespace string) (map[string]string, error) {
 	p := getter.All(o.Settings)
 	vals, err := o.ValueOpts.MergeValues(p)
 	if err != nil {
@@ -406,11 +899,10 @@ func getTemplateFile(o *options.LintOptions, chartRequested *chart.Chart) (map[s
 	}
 	top := map[string]interface{}{
 		"Chart":        chartRequested.Metadata,
-		"Capabilities": chartutil.DefaultCapabilities.Copy(),
-		// set Release undefined
+		"Capabilities": capabilities,
 		"Release": map[string]interface{}{
-			"Name":      "undefined",
-			"Namespace": "undefined",
+			"Name":      releaseName,
+			"Namespace": namespace,
 			"Revision":  1,
 			"Service":   "Helm",
 		},
@@ -0,0 +1,265 @@
+package extension
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// permissionsFilename is the file declaring the permissions the extension is installed with.
+const permissionsFilename = "permissions.yaml"
+
+// clusterScopedKinds are the well-known kubernetes kinds that can only be granted by a ClusterRole, the scopes of
+// the KubeSphere kinds are those of the bundled schemas, and the CRDs of --schemas and the chart are added when linting.
+var clusterScopedKinds = sets.New(
+	schema.GroupKind{Kind: "Namespace"},
+	schema.GroupKind{Kind: "Node"},
+	schema.GroupKind{Kind: "PersistentVolume"},
+	schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+	schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"},
+	schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+	schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"},
+	schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"},
+	schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"},
+	schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"},
+	schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicyBinding"},
+	schema.GroupKind{Group: "storage.k8s.io", Kind: "StorageClass"},
+	schema.GroupKind{Group: "storage.k8s.io", Kind: "CSIDriver"},
+	schema.GroupKind{Group: "storage.k8s.io", Kind: "VolumeAttachment"},
+	schema.GroupKind{Group: "scheduling.k8s.io", Kind: "PriorityClass"},
+	schema.GroupKind{Group: "networking.k8s.io", Kind: "IngressClass"},
+	schema.GroupKind{Group: "node.k8s.io", Kind: "RuntimeClass"},
+)
+
+// permissionRole is a ClusterRole or Role declared in permissions.yaml.
+type permissionRole struct {
+	Kind  string              `json:"kind"`
+	Rules []rbacv1.PolicyRule `json:"rules"`
+}
+
+// createdResource is a group resource the chart creates when it is installed.
+type createdResource struct {
+	// file is the name of the rendered template
+	file          string
+	resource      lint.Resource
+	groupResource schema.GroupResource
+	clusterScoped bool
+}
+
+// permissions decodes the roles in permissions.yaml, the file and the documents that can't be decoded
+// are returned as findings.
+func (c *lintContext) permissions() ([]permissionRole, []lint.Finding) {
+	i := slices.IndexFunc(c.chart.Files, func(f *chart.File) bool {
+		return f.Name == permissionsFilename
+	})
+	if i < 0 {
+		return nil, []lint.Finding{{
+			File:    permissionsFilename,
+			Message: fmt.Sprintf("%s is not found, the extension can't be installed without permissions", permissionsFilename),
+		}}
+	}
+
+	var roles []permissionRole
+	var malformed []lint.Finding
+	for j, m := range splitManifests(string(c.chart.Files[i].Data)) {
+		var role permissionRole
+		if err := yaml.Unmarshal([]byte(m), &role); err != nil {
+			malformed = append(malformed, lint.Finding{
+				File:    permissionsFilename,
+				Message: fmt.Sprintf("unable to decode YAML document %d: %v", j+1, err),
+			})
+			continue
+		}
+		switch role.Kind {
+		case "":
+			continue
+		case "ClusterRole", "Role":
+			roles = append(roles, role)
+		default:
+			malformed = append(malformed, lint.Finding{
+				File:    permissionsFilename,
+				Message: fmt.Sprintf("YAML document %d has unsupported kind %q, must be ClusterRole or Role", j+1, role.Kind),
+			})
+		}
+	}
+	return roles, malformed
+}
+
+// createdResources returns the group resources of the rendered manifests and the CRDs of the chart,
+// in the order they are rendered.
+func (c *lintContext) createdResources() ([]createdResource, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, err
+	}
+	crds := make(map[string]string)
+	for _, crd := range c.chart.CRDObjects() {
+		crds[crd.Filename] = string(crd.File.Data)
+	}
+	// malformed CRDs are reported by the schemas rule
+	crdObjects, _ := manifests(crds)
+	objects := append(slices.Clone(r.manifests), crdObjects...)
+
+	schemas, err := c.schemas()
+	if err != nil {
+		return nil, err
+	}
+	clusterScoped := clusterScopedKinds.Clone()
+	plurals := make(map[schema.GroupKind]string)
+	for gvk, s := range schemas {
+		plurals[gvk.GroupKind()] = s.plural
+		if s.clusterScoped {
+			clusterScoped.Insert(gvk.GroupKind())
+		}
+	}
+	for _, m := range objects {
+		if m.resource.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		group, _ := lookupValue(m.object, "spec.group").(string)
+		kind, _ := lookupValue(m.object, "spec.names.kind").(string)
+		plural, _ := lookupValue(m.object, "spec.names.plural").(string)
+		gk := schema.GroupKind{Group: group, Kind: kind}
+		if plural != "" {
+			plurals[gk] = plural
+		}
+		if scope, _ := lookupValue(m.object, "spec.scope").(string); scope == "Cluster" {
+			clusterScoped.Insert(gk)
+		}
+	}
+
+	var result []createdResource
+	for _, m := range objects {
+		apiVersion, _ := m.object["apiVersion"].(string)
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if m.resource.Kind == "" || err != nil {
+			continue
+		}
+		gk := gv.WithKind(m.resource.Kind).GroupKind()
+		resource, ok := plurals[gk]
+		if !ok {
+			plural, _ := meta.UnsafeGuessKindToResource(gv.WithKind(m.resource.Kind))
+			resource = plural.Resource
+		}
+		result = append(result, createdResource{
+			file:          m.file,
+			resource:      m.resource,
+			groupResource: schema.GroupResource{Group: gk.Group, Resource: resource},
+			clusterScoped: clusterScoped.Has(gk),
+		})
+	}
+	return result, nil
+}
+
+// allowsCreate reports whether rule allows to create the group resource.
+func allowsCreate(rule rbacv1.PolicyRule, gr schema.GroupResource) bool {
+	// resourceNames can't restrict create requests, which have no name yet
+	return len(rule.ResourceNames) == 0 &&
+		matchesAny(rule.Verbs, "create") &&
+		matchesAny(rule.APIGroups, gr.Group) &&
+		matchesAny(rule.Resources, gr.Resource)
+}
+
+func matchesAny(values []string, value string) bool {
+	return slices.Contains(values, rbacv1.ResourceAll) || slices.Contains(values, value)
+}
+
+// lintPermissions reports the resources created by the chart that the roles in permissions.yaml don't allow to create.
+func lintPermissions(c *lintContext) ([]lint.Finding, error) {
+	roles, findings := c.permissions()
+	if roles == nil && findings != nil {
+		return findings, nil
+	}
+	resources, err := c.createdResources()
+	if err != nil {
+		return nil, err
+	}
+
+	reported := sets.New[schema.GroupResource]()
+	for _, r := range resources {
+		if reported.Has(r.groupResource) {
+			continue
+		}
+		covered := slices.ContainsFunc(roles, func(role permissionRole) bool {
+			// a Role only grants namespaced resources in the namespace of the extension
+			if role.Kind == "Role" && r.clusterScoped {
+				return false
+			}
+			return slices.ContainsFunc(role.Rules, func(rule rbacv1.PolicyRule) bool {
+				return allowsCreate(rule, r.groupResource)
+			})
+		})
+		if covered {
+			continue
+		}
+		reported.Insert(r.groupResource)
+		message := fmt.Sprintf("creating %s is not allowed by any rule in %s", r.groupResource, permissionsFilename)
+		if r.clusterScoped {
+			message = fmt.Sprintf("creating %s is not allowed by any ClusterRole rule in %s", r.groupResource, permissionsFilename)
+		}
+		findings = append(findings, lint.Finding{
+			File:     renderedFilePath(r.file),
+			Resource: &r.resource,
+			Message:  message,
+		})
+	}
+	return findings, nil
+}
+
+// lintPermissionsWildcard reports the rules in permissions.yaml granting '*' verbs or resources,
+// with the resources the chart actually creates under them so that they can be narrowed down.
+func lintPermissionsWildcard(c *lintContext) ([]lint.Finding, error) {
+	// a missing or malformed permissions.yaml is reported by the permissions rule
+	roles, _ := c.permissions()
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	resources, err := c.createdResources()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []lint.Finding
+	for _, role := range roles {
+		for i, rule := range role.Rules {
+			if !slices.Contains(rule.Verbs, rbacv1.VerbAll) && !slices.Contains(rule.Resources, rbacv1.ResourceAll) {
+				continue
+			}
+			created := sets.New[string]()
+			for _, r := range resources {
+				if matchesAny(rule.APIGroups, r.groupResource.Group) && matchesAny(rule.Resources, r.groupResource.Resource) {
+					created.Insert(r.groupResource.String())
+				}
+			}
+			usage := "the chart creates none of them"
+			if created.Len() > 0 {
+				usage = fmt.Sprintf("the chart only creates [%s]", strings.Join(sets.List(created), ", "))
+			}
+			findings = append(findings, lint.Finding{
+				File: permissionsFilename,
+				Message: fmt.Sprintf("%s rule %d grants verbs [%s] on resources [%s] in API groups [%s], %s",
+					role.Kind, i+1, strings.Join(rule.Verbs, ", "), strings.Join(rule.Resources, ", "),
+					strings.Join(quoteGroups(rule.APIGroups), ", "), usage),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// quoteGroups quotes the API groups, so that the core group is readable.
+func quoteGroups(groups []string) []string {
+	result := make([]string, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, fmt.Sprintf("%q", g))
+	}
+	return result
+}
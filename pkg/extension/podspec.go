@@ -0,0 +1,191 @@
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// workloadPodTemplates are the paths of the pod templates in the built-in workload kinds,
+// an empty path means the resource itself is a pod.
+var workloadPodTemplates = map[string][]string{
+	"Pod":                   {""},
+	"PodTemplate":           {"template"},
+	"Deployment":            {"spec.template"},
+	"StatefulSet":           {"spec.template"},
+	"DaemonSet":             {"spec.template"},
+	"ReplicaSet":            {"spec.template"},
+	"ReplicationController": {"spec.template"},
+	"Job":                   {"spec.template"},
+	"CronJob":               {"spec.jobTemplate.spec.template"},
+}
+
+// renderedManifest is a YAML document of a rendered template.
+type renderedManifest struct {
+	// file is the name of the rendered template
+	file     string
+	resource lint.Resource
+	object   map[string]any
+	// source is the document as rendered, with its comments
+	source string
+}
+
+// renderedPodSpec is a pod spec found in the rendered manifests.
+type renderedPodSpec struct {
+	// file is the name of the rendered template
+	file     string
+	resource lint.Resource
+	// annotations are the annotations of the workload
+	annotations map[string]any
+	spec        *corev1.PodSpec
+}
+
+// rendering is the chart rendered with a combination of the values matrix, which is rendered once
+// and shared by all rules of the combination.
+type rendering struct {
+	manifests []renderedManifest
+	podSpecs  []renderedPodSpec
+	// malformed are the documents and pod templates that can't be decoded
+	malformed []lint.Finding
+	// schemas are the schemas of the rendered manifests and the findings of the CRDs that can't be loaded,
+	// see lintContext.schemaObjects
+	schemas        schemaSet
+	schemaFindings []lint.Finding
+}
+
+func (c *lintContext) newRendering(files map[string]string) *rendering {
+	objects, malformed := manifests(files)
+	specs, malformedSpecs := c.podSpecs(objects)
+	return &rendering{
+		manifests: objects,
+		podSpecs:  specs,
+		malformed: append(malformed, malformedSpecs...),
+	}
+}
+
+// manifests decodes the YAML documents of the rendered files, the documents that can't be decoded
+// are returned as findings instead of failing the lint run.
+func manifests(files map[string]string) ([]renderedManifest, []lint.Finding) {
+	var result []renderedManifest
+	var malformed []lint.Finding
+	for _, filename := range slices.Sorted(maps.Keys(files)) {
+		if !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") {
+			continue
+		}
+
+		for i, m := range splitManifests(files[filename]) {
+			var object map[string]any
+			if err := yaml.Unmarshal([]byte(m), &object); err != nil {
+				malformed = append(malformed, lint.Finding{
+					File:    renderedFilePath(filename),
+					Message: fmt.Sprintf("unable to decode YAML document %d: %v", i+1, err),
+				})
+				continue
+			}
+			if object == nil {
+				continue
+			}
+			kind, _ := object["kind"].(string)
+			metadata, _ := object["metadata"].(map[string]any)
+			name, _ := metadata["name"].(string)
+			namespace, _ := metadata["namespace"].(string)
+			result = append(result, renderedManifest{
+				file:     filename,
+				resource: lint.Resource{Kind: kind, Namespace: namespace, Name: name},
+				object:   object,
+				source:   m,
+			})
+		}
+	}
+	return result, malformed
+}
+
+// podTemplatePaths returns the pod template paths of kind, including those configured for custom kinds.
+func (c *lintContext) podTemplatePaths(kind string) []string {
+	return append(slices.Clone(workloadPodTemplates[kind]), c.podTemplates[kind]...)
+}
+
+// podSpecs returns the pod specs of all workloads in the rendered manifests, so that every builtin rule
+// inspects the same set of pods. Pod templates that can't be decoded are returned as findings.
+func (c *lintContext) podSpecs(objects []renderedManifest) ([]renderedPodSpec, []lint.Finding) {
+	var specs []renderedPodSpec
+	var malformed []lint.Finding
+	for _, m := range objects {
+		for _, p := range c.podTemplatePaths(m.resource.Kind) {
+			templates := lookupPath(m.object, p)
+			// the pod template of a built-in workload is required, it may be missing when the spec is templated out
+			if len(templates) == 0 && slices.Contains(workloadPodTemplates[m.resource.Kind], p) {
+				malformed = append(malformed, lint.Finding{
+					File:     renderedFilePath(m.file),
+					Resource: &m.resource,
+					Message:  fmt.Sprintf("the pod template at %q is not found", p),
+				})
+			}
+			for _, t := range templates {
+				template, err := decodePodTemplate(t)
+				if err != nil {
+					malformed = append(malformed, lint.Finding{
+						File:     renderedFilePath(m.file),
+						Resource: &m.resource,
+						Message:  fmt.Sprintf("unable to decode the pod template at %q: %v", p, err),
+					})
+					continue
+				}
+				annotations, _ := lookupValue(m.object, "metadata.annotations").(map[string]any)
+				specs = append(specs, renderedPodSpec{
+					file:        m.file,
+					resource:    m.resource,
+					annotations: annotations,
+					spec:        &template.Spec,
+				})
+			}
+		}
+	}
+	return specs, malformed
+}
+
+func decodePodTemplate(v any) (corev1.PodTemplateSpec, error) {
+	var template corev1.PodTemplateSpec
+	data, err := json.Marshal(v)
+	if err != nil {
+		return template, err
+	}
+	err = json.Unmarshal(data, &template)
+	return template, err
+}
+
+// lookupPath returns the values at the dot separated path in v, a list on the path is expanded to all its items.
+func lookupPath(v any, path string) []any {
+	if path == "" {
+		return []any{v}
+	}
+	field, rest, _ := strings.Cut(path, ".")
+	switch value := v.(type) {
+	case map[string]any:
+		if item, ok := value[field]; ok {
+			return lookupPath(item, rest)
+		}
+	case []any:
+		var result []any
+		for _, item := range value {
+			result = append(result, lookupPath(item, path)...)
+		}
+		return result
+	}
+	return nil
+}
+
+// lookupValue returns the first value at the dot separated path in v, or nil if there is none.
+func lookupValue(v any, path string) any {
+	if values := lookupPath(v, path); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
@@ -202,9 +202,38 @@ func Load(path string) (*api.Extension, error) {
 	}
 
 	extension.ChartData = chartContent
+	if err = loadDocs(&extension, tempDir); err != nil {
+		return nil, err
+	}
 	return &extension, nil
 }
 
+// loadDocs loads the localized README and CHANGELOG files of the extension from its directory,
+// in the languages the extension supports, which have both displayName and description.
+func loadDocs(extension *api.Extension, dir string) error {
+	extension.README = corev1alpha1.Locales{}
+	extension.Changelog = corev1alpha1.Locales{}
+	for lang := range extension.Metadata.DisplayName {
+		if _, ok := extension.Metadata.Description[lang]; !ok {
+			continue
+		}
+		for filename, docs := range map[string]corev1alpha1.Locales{
+			api.ReadmeFilename(lang):    extension.README,
+			api.ChangelogFilename(lang): extension.Changelog,
+		} {
+			data, err := os.ReadFile(filepath.Join(dir, filename))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			docs[lang] = corev1alpha1.LocaleString(data)
+		}
+	}
+	return nil
+}
+
 func LoadFromHelm(path string) (*api.Extension, error) {
 	tempDir, err := os.MkdirTemp("", "chart")
 	if err != nil {
@@ -256,5 +285,8 @@ func LoadFromHelm(path string) (*api.Extension, error) {
 	extension.Metadata = metadata
 	extension.ChartURL = path + ":" + version
 
+	if err = loadDocs(&extension, filepath.Join(tempDir, filepath.Base(path))); err != nil {
+		return nil, err
+	}
 	return &extension, nil
 }
@@ -0,0 +1,355 @@
+package extension
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuralpruning "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/applyconfigurations"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	smdschema "sigs.k8s.io/structured-merge-diff/v4/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// bundledSchemas are the CustomResourceDefinitions of the KubeSphere kinds extensions create, which rendered custom
+// resources are validated against and which tell the scopes of the kinds. They are maintained by hand after the API types
+// of kubesphere.io/api, with the fields lint checks, and the fields of the others left open by x-kubernetes-preserve-unknown-fields.
+//
+//go:embed schemas
+var bundledSchemas embed.FS
+
+// crdKind is the kind of the CustomResourceDefinitions in the chart and the schema directories.
+var crdKind = apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+
+// crdSchema is the schema of a version of a CustomResourceDefinition.
+type crdSchema struct {
+	validator  *validate.SchemaValidator
+	structural *structuralschema.Structural
+	// plural is the resource of the kind, and clusterScoped whether it is cluster-scoped
+	plural        string
+	clusterScoped bool
+}
+
+// schemaSet is the schemas of custom resources keyed by their group version kind.
+type schemaSet map[schema.GroupVersionKind]*crdSchema
+
+// add adds the schemas of all versions of the CustomResourceDefinition.
+func (s schemaSet) add(data []byte) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.UnmarshalStrict(data, crd); err != nil {
+		return fmt.Errorf("unable to decode CustomResourceDefinition: %w", err)
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		props := &apiextensions.JSONSchemaProps{}
+		if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, props, nil); err != nil {
+			return fmt.Errorf("invalid schema of CustomResourceDefinition %s version %s: %w", crd.Name, v.Name, err)
+		}
+		structural, err := structuralschema.NewStructural(props)
+		if err != nil {
+			return fmt.Errorf("invalid schema of CustomResourceDefinition %s version %s: %w", crd.Name, v.Name, err)
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+		s[gvk] = &crdSchema{
+			validator:     validate.NewSchemaValidator(structural.ToKubeOpenAPI(), nil, "", strfmt.Default),
+			structural:    structural,
+			plural:        crd.Spec.Names.Plural,
+			clusterScoped: crd.Spec.Scope == apiextensionsv1.ClusterScoped,
+		}
+	}
+	return nil
+}
+
+// addManifests adds the CustomResourceDefinitions of a multi-document YAML file, other documents are ignored.
+func (s schemaSet) addManifests(content string) error {
+	for _, m := range splitManifests(content) {
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal([]byte(m), &typeMeta); err != nil {
+			return err
+		}
+		if typeMeta.GroupVersionKind() != crdKind {
+			continue
+		}
+		if err := s.add([]byte(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDir adds the CustomResourceDefinitions of all YAML and JSON files under dir.
+func (s schemaSet) addDir(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(path)) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		if err := s.addManifests(string(data)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// schemas returns the bundled schemas and those of --schemas, which are loaded once for the extension.
+// The CRDs of the chart are added by the caller to the returned copy, so that their problems are reported as findings.
+func (c *lintContext) schemas() (schemaSet, error) {
+	if c.schemaSet == nil {
+		s := make(schemaSet)
+		if err := s.addDir(bundledSchemas, "schemas"); err != nil {
+			return nil, fmt.Errorf("failed to load the bundled schemas: %w", err)
+		}
+		if c.options.Schemas != "" {
+			if err := s.addDir(os.DirFS(c.options.Schemas), "."); err != nil {
+				return nil, fmt.Errorf("failed to load schemas from %s: %w", c.options.Schemas, err)
+			}
+		}
+		c.schemaSet = s
+	}
+	return maps.Clone(c.schemaSet), nil
+}
+
+// schemaObjects renders the chart and returns the schemas to validate the rendered manifests against,
+// the manifests, and the findings of the CRDs of the chart that can't be loaded. The schemas are loaded
+// once for the rules of a combination of the values matrix.
+func (c *lintContext) schemaObjects() (schemaSet, []renderedManifest, []lint.Finding, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if r.schemas == nil {
+		if r.schemas, r.schemaFindings, err = c.loadSchemas(r); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return r.schemas, r.manifests, slices.Clone(r.schemaFindings), nil
+}
+
+// loadSchemas returns the schemas of the rendering, with the CRDs the chart has and renders,
+// and the findings of those that can't be loaded.
+func (c *lintContext) loadSchemas(r *rendering) (schemaSet, []lint.Finding, error) {
+	schemas, err := c.schemas()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var findings []lint.Finding
+	for _, crd := range c.chart.CRDObjects() {
+		if err := schemas.addManifests(string(crd.File.Data)); err != nil {
+			findings = append(findings, lint.Finding{
+				File:    renderedFilePath(crd.Filename),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	for _, m := range r.manifests {
+		if m.resource.Kind != crdKind.Kind {
+			continue
+		}
+		data, err := yaml.Marshal(m.object)
+		if err == nil {
+			err = schemas.add(data)
+		}
+		if err != nil {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(m.file),
+				Resource: &m.resource,
+				Message:  err.Error(),
+			})
+		}
+	}
+	return schemas, findings, nil
+}
+
+// objectKind returns the group version kind of a rendered manifest.
+func objectKind(m renderedManifest) (schema.GroupVersionKind, bool) {
+	apiVersion, _ := m.object["apiVersion"].(string)
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || apiVersion == "" || m.resource.Kind == "" {
+		return schema.GroupVersionKind{}, false
+	}
+	return gv.WithKind(m.resource.Kind), true
+}
+
+// lintSchemas validates the rendered manifests against the schemas of the kubernetes kinds known to client-go,
+// the bundled KubeSphere CRDs, the CRDs of the chart and those of --schemas.
+func lintSchemas(c *lintContext) ([]lint.Finding, error) {
+	schemas, objects, findings, err := c.schemaObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	converter := applyconfigurations.NewTypeConverter(kscheme.Scheme)
+	for _, m := range objects {
+		report := func(format string, args ...any) {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(m.file),
+				Resource: &m.resource,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		gvk, ok := objectKind(m)
+		switch {
+		case !ok:
+			report("apiVersion and kind are required")
+		case gvk == crdKind:
+			// CustomResourceDefinitions are decoded when their schemas are loaded
+		case schemas[gvk] != nil:
+			s := schemas[gvk]
+			for _, e := range s.validator.Validate(m.object).Errors {
+				report("%s", strings.Replace(e.Error(), " in body", "", 1))
+			}
+			unknown := structuralpruning.PruneWithOptions(runtime.DeepCopyJSON(m.object), s.structural, true,
+				structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true})
+			for _, p := range unknown {
+				report("%s: unknown field", p)
+			}
+		case kscheme.Scheme.Recognizes(gvk):
+			// the typed value of an empty object tells the schema of the kind
+			empty, err := converter.ObjectToTyped(&unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": gvk.GroupVersion().String(),
+				"kind":       gvk.Kind,
+			}})
+			if err != nil {
+				return nil, err
+			}
+			for _, problem := range validateValue(empty.Schema(), empty.TypeRef(), m.object, "") {
+				report("%s", problem)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// validateValue reports the fields of v that are not declared in the structured-merge-diff schema or are of the wrong type.
+// Unlike the validation of structured-merge-diff, which stops at the first undeclared field of a map, it reports
+// every problem in a stable order.
+func validateValue(s *smdschema.Schema, tr smdschema.TypeRef, v any, path string) []string {
+	atom, ok := s.Resolve(tr)
+	if !ok || v == nil {
+		return nil
+	}
+	mismatch := func(got string) []string {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", strings.TrimPrefix(path, "."), atomKind(atom), got)}
+	}
+
+	var problems []string
+	switch value := v.(type) {
+	case map[string]any:
+		if atom.Map == nil {
+			return mismatch("object")
+		}
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			child := path + "." + key
+			elementType := atom.Map.ElementType
+			if f, ok := atom.Map.FindField(key); ok {
+				elementType = f.Type
+			} else if elementType == (smdschema.TypeRef{}) {
+				problems = append(problems, fmt.Sprintf("%s: unknown field", strings.TrimPrefix(child, ".")))
+				continue
+			}
+			problems = append(problems, validateValue(s, elementType, value[key], child)...)
+		}
+	case []any:
+		if atom.List == nil {
+			return mismatch("list")
+		}
+		for i, item := range value {
+			problems = append(problems, validateValue(s, atom.List.ElementType, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	default:
+		if atom.Scalar == nil {
+			return mismatch(fmt.Sprintf("%v", value))
+		}
+		switch *atom.Scalar {
+		case smdschema.Numeric:
+			switch value.(type) {
+			case int64, float64:
+			default:
+				return mismatch(fmt.Sprintf("%q", fmt.Sprint(value)))
+			}
+		case smdschema.String:
+			if _, ok := value.(string); !ok {
+				return mismatch(fmt.Sprintf("%v", value))
+			}
+		case smdschema.Boolean:
+			if _, ok := value.(bool); !ok {
+				return mismatch(fmt.Sprintf("%q", fmt.Sprint(value)))
+			}
+		}
+	}
+	return problems
+}
+
+// atomKind returns the kind of values the schema atom accepts.
+func atomKind(atom smdschema.Atom) string {
+	switch {
+	case atom.Scalar != nil:
+		return string(*atom.Scalar)
+	case atom.Map != nil:
+		return "object"
+	default:
+		return "list"
+	}
+}
+
+// lintSchemaCoverage reports the kinds of rendered manifests without a schema, which are not validated.
+func lintSchemaCoverage(c *lintContext) ([]lint.Finding, error) {
+	schemas, objects, _, err := c.schemaObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	// report every kind once, on the first resource of it
+	first := make(map[schema.GroupVersionKind]renderedManifest)
+	for _, m := range objects {
+		gvk, ok := objectKind(m)
+		if !ok || gvk == crdKind || schemas[gvk] != nil || kscheme.Scheme.Recognizes(gvk) {
+			continue
+		}
+		if _, ok := first[gvk]; !ok {
+			first[gvk] = m
+		}
+	}
+
+	var findings []lint.Finding
+	for _, gvk := range slices.SortedFunc(maps.Keys(first), func(a, b schema.GroupVersionKind) int {
+		return strings.Compare(a.String(), b.String())
+	}) {
+		m := first[gvk]
+		findings = append(findings, lint.Finding{
+			File:     renderedFilePath(m.file),
+			Resource: &m.resource,
+			Message: fmt.Sprintf("no schema of %s %s is found, add its CustomResourceDefinition to --schemas to validate it",
+				gvk.GroupVersion(), gvk.Kind),
+		})
+	}
+	return findings, nil
+}
@@ -0,0 +1,228 @@
+package extension
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// helmHookAnnotation marks the resources helm creates for hooks, such as the test pods of the scaffold.
+const helmHookAnnotation = "helm.sh/hook"
+
+// podContainer is a container of a pod spec with the kind of container it is.
+type podContainer struct {
+	// kind is one of "container", "init container" and "ephemeral container"
+	kind      string
+	container corev1.Container
+}
+
+func (p podContainer) String() string {
+	return fmt.Sprintf("%s %s", p.kind, p.container.Name)
+}
+
+// podContainers returns all containers of the pod spec.
+func podContainers(spec *corev1.PodSpec) []podContainer {
+	var result []podContainer
+	for _, c := range spec.InitContainers {
+		result = append(result, podContainer{kind: "init container", container: c})
+	}
+	for _, c := range spec.Containers {
+		result = append(result, podContainer{kind: "container", container: c})
+	}
+	for _, c := range spec.EphemeralContainers {
+		result = append(result, podContainer{kind: "ephemeral container", container: corev1.Container(c.EphemeralContainerCommon)})
+	}
+	return result
+}
+
+// lintPodSpecs renders the chart and reports the problems check returns for every pod spec.
+func lintPodSpecs(c *lintContext, check func(p renderedPodSpec) []string) ([]lint.Finding, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []lint.Finding
+	for _, p := range r.podSpecs {
+		for _, problem := range check(p) {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(p.file),
+				Resource: &p.resource,
+				Message:  problem,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// lintPrivileged reports privileged containers and containers that can gain more privileges than their parent process.
+func lintPrivileged(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		var problems []string
+		for _, pc := range podContainers(p.spec) {
+			sc := pc.container.SecurityContext
+			if sc == nil {
+				continue
+			}
+			if sc.Privileged != nil && *sc.Privileged {
+				problems = append(problems, fmt.Sprintf("%s is privileged", pc))
+			}
+			if sc.Capabilities != nil {
+				for _, capability := range sc.Capabilities.Add {
+					if capability == "ALL" || capability == "SYS_ADMIN" {
+						problems = append(problems, fmt.Sprintf("%s adds the %s capability", pc, capability))
+					}
+				}
+			}
+		}
+		return problems
+	})
+}
+
+// lintHostAccess reports pods sharing the namespaces of the host and mounting paths of the host.
+func lintHostAccess(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		var problems []string
+		if p.spec.HostNetwork {
+			problems = append(problems, "the pod uses the host network")
+		}
+		if p.spec.HostPID {
+			problems = append(problems, "the pod uses the host PID namespace")
+		}
+		if p.spec.HostIPC {
+			problems = append(problems, "the pod uses the host IPC namespace")
+		}
+		for _, v := range p.spec.Volumes {
+			if v.HostPath != nil {
+				problems = append(problems, fmt.Sprintf("volume %s mounts the host path %s", v.Name, v.HostPath.Path))
+			}
+		}
+		for _, pc := range podContainers(p.spec) {
+			for _, port := range pc.container.Ports {
+				if port.HostPort != 0 {
+					problems = append(problems, fmt.Sprintf("%s binds the host port %d", pc, port.HostPort))
+				}
+			}
+		}
+		return problems
+	})
+}
+
+// lintResources reports containers without cpu and memory requests or limits, ephemeral containers can't have resources.
+func lintResources(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		var problems []string
+		for _, pc := range podContainers(p.spec) {
+			if pc.kind == "ephemeral container" {
+				continue
+			}
+			resources := pc.container.Resources
+			for _, list := range []struct {
+				name      string
+				resources corev1.ResourceList
+			}{
+				{name: "requests", resources: resources.Requests},
+				{name: "limits", resources: resources.Limits},
+			} {
+				var missing []string
+				for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+					if _, ok := list.resources[name]; !ok {
+						missing = append(missing, string(name))
+					}
+				}
+				if len(missing) > 0 {
+					problems = append(problems, fmt.Sprintf("%s has no %s %s", pc, strings.Join(missing, " and "), list.name))
+				}
+			}
+		}
+		return problems
+	})
+}
+
+// lintImageTags reports images without a tag or digest, or with the latest tag, which are not reproducible.
+func lintImageTags(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		var problems []string
+		for _, pc := range podContainers(p.spec) {
+			named, err := reference.ParseNormalizedNamed(pc.container.Image)
+			if err != nil {
+				// invalid images are reported by the images rule
+				continue
+			}
+			if _, ok := named.(reference.Digested); ok {
+				continue
+			}
+			tagged, ok := named.(reference.Tagged)
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s uses image %s without a tag, which pulls the latest one", pc, pc.container.Image))
+			case tagged.Tag() == "latest":
+				problems = append(problems, fmt.Sprintf("%s uses image %s with the latest tag", pc, pc.container.Image))
+			}
+		}
+		return problems
+	})
+}
+
+// lintRunAsRoot reports containers that run as root, or may run as root because the image decides the user.
+func lintRunAsRoot(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		var runAsUser *int64
+		var runAsNonRoot *bool
+		if sc := p.spec.SecurityContext; sc != nil {
+			runAsUser, runAsNonRoot = sc.RunAsUser, sc.RunAsNonRoot
+		}
+
+		var problems []string
+		for _, pc := range podContainers(p.spec) {
+			user, nonRoot := runAsUser, runAsNonRoot
+			if sc := pc.container.SecurityContext; sc != nil {
+				if sc.RunAsUser != nil {
+					user = sc.RunAsUser
+				}
+				if sc.RunAsNonRoot != nil {
+					nonRoot = sc.RunAsNonRoot
+				}
+			}
+			switch {
+			case user != nil && *user == 0:
+				problems = append(problems, fmt.Sprintf("%s runs as root", pc))
+			case user == nil && (nonRoot == nil || !*nonRoot):
+				problems = append(problems, fmt.Sprintf("%s may run as root, set runAsNonRoot or a non-root runAsUser", pc))
+			}
+		}
+		return problems
+	})
+}
+
+// lintProbes reports the containers of long-running workloads without readiness or liveness probes,
+// jobs and helm hooks run to completion and don't need them.
+func lintProbes(c *lintContext) ([]lint.Finding, error) {
+	return lintPodSpecs(c, func(p renderedPodSpec) []string {
+		if p.resource.Kind == "Job" || p.resource.Kind == "CronJob" {
+			return nil
+		}
+		if _, ok := p.annotations[helmHookAnnotation]; ok {
+			return nil
+		}
+
+		var problems []string
+		for _, container := range p.spec.Containers {
+			var missing []string
+			if container.ReadinessProbe == nil {
+				missing = append(missing, "readiness")
+			}
+			if container.LivenessProbe == nil {
+				missing = append(missing, "liveness")
+			}
+			if len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("container %s has no %s probe", container.Name, strings.Join(missing, " or ")))
+			}
+		}
+		return problems
+	})
+}
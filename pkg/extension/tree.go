@@ -0,0 +1,219 @@
+package extension
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/helm"
+)
+
+// Tree is an extension with its subcharts, loaded once for both lint phases. The packaged charts, the extension
+// itself or its subcharts, are expanded into a temporary directory which Close removes.
+type Tree struct {
+	// path is the extension directory or packaged file given on the command line
+	path string
+	// dir is the directory of the extension, path itself or where it is expanded
+	dir string
+	// metadata is extension.yaml without encoding the icon, so that the builtin rules can check the local files
+	// it refers to, it is nil when the path is a plain helm chart
+	metadata *api.Metadata
+	// chart is the extension chart with its subcharts for the builtin rules
+	chart *chart.Chart
+	// charts are the root chart and the subcharts the helm linter runs against
+	charts  []treeChart
+	tempDir string
+}
+
+// treeChart is a chart of a Tree on disk.
+type treeChart struct {
+	// path is the path of the chart as reported, packaged subcharts are reported by their archives,
+	// e.g. extension/charts/frontend-0.1.0.tgz/charts/nested
+	path string
+	// dir is the directory of the chart, where it is expanded for a packaged subchart
+	dir string
+	// rendered is the directory of the chart in the names of the rendered templates, relative to the extension,
+	// subcharts are rendered by their names whether they are packaged or not, e.g. charts/frontend/charts/nested
+	rendered string
+	// metadata is the metadata of the chart the helm linter checks, converted from extension.yaml for the root
+	metadata *chart.Metadata
+}
+
+// LoadTrees loads the extensions at paths, and their subcharts when withSubcharts is set.
+// The caller must close the trees by CloseTrees to remove their temporary files.
+func LoadTrees(paths []string, withSubcharts bool) ([]*Tree, error) {
+	var trees []*Tree
+	for _, path := range paths {
+		t, err := loadTree(path, withSubcharts)
+		if err != nil {
+			CloseTrees(trees)
+			return nil, err
+		}
+		trees = append(trees, t)
+	}
+	return trees, nil
+}
+
+// CloseTrees closes all the trees.
+func CloseTrees(trees []*Tree) {
+	for _, t := range trees {
+		_ = t.Close()
+	}
+}
+
+func loadTree(path string, withSubcharts bool) (*Tree, error) {
+	t := &Tree{path: path, dir: path}
+	if err := t.load(withSubcharts); err != nil {
+		_ = t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tree) load(withSubcharts bool) error {
+	fi, err := os.Stat(t.path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		if t.dir, err = t.expand(t.path); err != nil {
+			return fmt.Errorf("unable to expand %s: %w", t.path, err)
+		}
+	}
+
+	root := treeChart{path: t.path, dir: t.dir}
+	if _, err := os.Stat(filepath.Join(t.dir, api.MetadataFilename)); err == nil {
+		if t.metadata, err = api.LoadMetadata(t.dir, api.WithEncodeIcon(false)); err != nil {
+			return err
+		}
+		if t.chart, err = helm.Load(t.dir, t.metadata.ToChartYaml()); err != nil {
+			return err
+		}
+		// the helm linter checks the chart as it is published, with the icon encoded
+		metadata, err := api.LoadMetadata(t.dir)
+		if err != nil {
+			return err
+		}
+		root.metadata = metadata.ToChartYaml()
+	} else if root.metadata, err = chartutil.LoadChartfile(filepath.Join(t.dir, "Chart.yaml")); err != nil {
+		return err
+	}
+	t.charts = append(t.charts, root)
+
+	if withSubcharts {
+		return t.addSubcharts(root)
+	}
+	return nil
+}
+
+// addSubcharts adds the subcharts in the charts directory of the chart recursively.
+func (t *Tree) addSubcharts(parent treeChart) error {
+	entries, err := os.ReadDir(filepath.Join(parent.dir, "charts"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		sub := treeChart{
+			path: filepath.Join(parent.path, "charts", e.Name()),
+			dir:  filepath.Join(parent.dir, "charts", e.Name()),
+		}
+		switch {
+		case e.IsDir():
+			if _, err := os.Stat(filepath.Join(sub.dir, "Chart.yaml")); err != nil {
+				continue
+			}
+		case strings.HasSuffix(e.Name(), ".tgz") || strings.HasSuffix(e.Name(), ".tar.gz"):
+			if sub.dir, err = t.expand(sub.dir); err != nil {
+				return fmt.Errorf("unable to expand %s: %w", sub.path, err)
+			}
+		default:
+			continue
+		}
+		if sub.metadata, err = chartutil.LoadChartfile(filepath.Join(sub.dir, "Chart.yaml")); err != nil {
+			return err
+		}
+		sub.rendered = path.Join(parent.rendered, "charts", sub.metadata.Name)
+		t.charts = append(t.charts, sub)
+		if err := t.addSubcharts(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chartAt returns the chart of the tree at the path as reported.
+func (t *Tree) chartAt(reported string) (treeChart, bool) {
+	for _, c := range t.charts {
+		if c.path == reported {
+			return c, true
+		}
+	}
+	return treeChart{}, false
+}
+
+// Path returns the extension directory or packaged file the tree is loaded from.
+func (t *Tree) Path() string {
+	return t.path
+}
+
+// IsExtension reports whether the tree is an extension, rather than a plain helm chart without extension.yaml
+// which only the helm linter runs against.
+func (t *Tree) IsExtension() bool {
+	return t.metadata != nil
+}
+
+// expand expands a packaged chart or extension into the temporary directory and returns the directory of it.
+// Charts are packaged into gzipped tarballs, and extensions may also be zip files.
+func (t *Tree) expand(path string) (string, error) {
+	if t.tempDir == "" {
+		tempDir, err := os.MkdirTemp("", "ksbuilder-lint")
+		if err != nil {
+			return "", err
+		}
+		t.tempDir = tempDir
+	}
+	dir, err := os.MkdirTemp(t.tempDir, filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasSuffix(path, ".tgz") && !strings.HasSuffix(path, ".tar.gz") {
+		return dir, WriteFilesToTempDir(path, dir)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close() // nolint
+	if err := chartutil.Expand(dir, file); err != nil {
+		return "", err
+	}
+	// a chart archive has a single directory named after the chart
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return "", fmt.Errorf("unexpected files in the chart archive, it must have a single directory")
+	}
+	return filepath.Join(dir, entries[0].Name()), nil
+}
+
+// Close removes the temporary files of the tree.
+func (t *Tree) Close() error {
+	if t.tempDir == "" {
+		return nil
+	}
+	return os.RemoveAll(t.tempDir)
+}
@@ -0,0 +1,237 @@
+package extension
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubesphere/ksbuilder/cmd/options"
+	"github.com/kubesphere/ksbuilder/pkg/api"
+	"github.com/kubesphere/ksbuilder/pkg/lint"
+)
+
+// constraintVersion matches the versions in a semver constraint, including wildcards such as 4.x.
+var constraintVersion = regexp.MustCompile(`v?\d+(\.(\d+|[xX*])){0,2}(-[0-9A-Za-z.-]+)?`)
+
+// versionBound is larger than any version a constraint is expected to mention.
+const versionBound = 999999
+
+// targetCapabilities returns the capabilities to render the chart with, those of --target-kube-version if it is set.
+func targetCapabilities(o *options.LintOptions) (*chartutil.Capabilities, error) {
+	if o.TargetKSVersion != "" {
+		if _, err := semver.NewVersion(o.TargetKSVersion); err != nil {
+			return nil, fmt.Errorf("invalid --target-ks-version %q: %w", o.TargetKSVersion, err)
+		}
+	}
+	capabilities := chartutil.DefaultCapabilities.Copy()
+	if o.TargetKubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(o.TargetKubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --target-kube-version %q: %w", o.TargetKubeVersion, err)
+		}
+		capabilities.KubeVersion = *kubeVersion
+	}
+	return capabilities, nil
+}
+
+// satisfiable reports whether any version satisfies the constraint. The satisfying versions of a constraint
+// are ranges bounded by the versions it mentions, so it probes the mentioned versions and their neighbours.
+func satisfiable(constraint *semver.Constraints, text string) bool {
+	probes := []*semver.Version{semver.New(0, 0, 0, "", ""), semver.New(versionBound, 0, 0, "", "")}
+	for _, s := range constraintVersion.FindAllString(text, -1) {
+		v, err := semver.NewVersion(wildcardToZero(s))
+		if err != nil {
+			continue
+		}
+		major, minor, patch := v.Major(), v.Minor(), v.Patch()
+		probes = append(probes, v,
+			semver.New(major, minor, patch, "", ""),
+			semver.New(major, minor, patch+1, "", ""),
+			semver.New(major, minor+1, 0, "", ""),
+			semver.New(major+1, 0, 0, "", ""))
+		switch {
+		case patch > 0:
+			probes = append(probes, semver.New(major, minor, patch-1, "", ""))
+		case minor > 0:
+			probes = append(probes, semver.New(major, minor-1, versionBound, "", ""))
+		case major > 0:
+			probes = append(probes, semver.New(major-1, versionBound, versionBound, "", ""))
+		}
+	}
+	for _, v := range probes {
+		if constraint.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+var wildcard = regexp.MustCompile(`[xX*]`)
+
+func wildcardToZero(s string) string {
+	return wildcard.ReplaceAllString(s, "0")
+}
+
+// versionConstraint is a version constraint of extension.yaml and the version it is checked against.
+type versionConstraint struct {
+	field  string
+	value  string
+	target string
+}
+
+func (c *lintContext) versionConstraints() []versionConstraint {
+	return []versionConstraint{
+		{field: "ksVersion", value: c.metadata.KSVersion, target: c.options.TargetKSVersion},
+		{field: "kubeVersion", value: c.metadata.KubeVersion, target: c.options.TargetKubeVersion},
+	}
+}
+
+// lintVersionConstraints reports ksVersion and kubeVersion that are not semver constraints or can never be satisfied.
+func lintVersionConstraints(c *lintContext) ([]lint.Finding, error) {
+	var findings []lint.Finding
+	for _, vc := range c.versionConstraints() {
+		if vc.value == "" {
+			continue
+		}
+		constraint, err := semver.NewConstraint(vc.value)
+		if err != nil {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("%s %q is not a valid semver constraint: %v", vc.field, vc.value, err),
+			})
+			continue
+		}
+		if !satisfiable(constraint, vc.value) {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("%s %q can never be satisfied", vc.field, vc.value),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// lintTargetVersions reports the target versions that ksVersion or kubeVersion don't allow,
+// and the rendered resources whose APIs are removed from the target Kubernetes version.
+func lintTargetVersions(c *lintContext) ([]lint.Finding, error) {
+	var findings []lint.Finding
+	for _, vc := range c.versionConstraints() {
+		if vc.value == "" || vc.target == "" {
+			continue
+		}
+		constraint, err := semver.NewConstraint(vc.value)
+		if err != nil {
+			// reported by the version-constraints rule
+			continue
+		}
+		if v, err := semver.NewVersion(vc.target); err == nil && !constraint.Check(v) {
+			findings = append(findings, lint.Finding{
+				File:    api.MetadataFilename,
+				Message: fmt.Sprintf("%s %q doesn't allow the target version %s", vc.field, vc.value, vc.target),
+			})
+		}
+	}
+
+	if c.options.TargetKubeVersion == "" {
+		return findings, nil
+	}
+	objects, err := c.apiObjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if c.removedInTarget(o.lifecycle) {
+			findings = append(findings, lint.Finding{
+				File:     renderedFilePath(o.file),
+				Resource: &o.resource,
+				Message: fmt.Sprintf("unavailable in the target Kubernetes version %s: %s",
+					c.capabilities.KubeVersion.Version, o.lifecycle.message(o.gvk)),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// lintDeprecatedAPIs reports the rendered resources whose APIs are deprecated but still served in the target Kubernetes version.
+// Without --target-kube-version, deprecated APIs are reported by the helm linter.
+func lintDeprecatedAPIs(c *lintContext) ([]lint.Finding, error) {
+	if c.options.TargetKubeVersion == "" {
+		return nil, nil
+	}
+	objects, err := c.apiObjects()
+	if err != nil {
+		return nil, err
+	}
+	major, minor := c.targetKubeMajorMinor()
+	var findings []lint.Finding
+	for _, o := range objects {
+		if o.lifecycle.deprecatedMinor == 0 || major < 1 || (major == 1 && minor < o.lifecycle.deprecatedMinor) {
+			continue
+		}
+		// removed APIs are reported by the target-versions rule
+		if c.removedInTarget(o.lifecycle) {
+			continue
+		}
+		findings = append(findings, lint.Finding{
+			File:     renderedFilePath(o.file),
+			Resource: &o.resource,
+			Message:  o.lifecycle.message(o.gvk),
+		})
+	}
+	return findings, nil
+}
+
+// removedInTarget reports whether the API is unavailable in the target Kubernetes version.
+func (c *lintContext) removedInTarget(l apiLifecycle) bool {
+	if l.removedMinor == 0 {
+		return false
+	}
+	major, minor := c.targetKubeMajorMinor()
+	return major > 1 || (major == 1 && l.removedMinor <= minor)
+}
+
+// apiObject is a rendered resource of a Kubernetes API that is deprecated or removed.
+type apiObject struct {
+	// file is the name of the rendered template
+	file      string
+	resource  lint.Resource
+	gvk       schema.GroupVersionKind
+	lifecycle apiLifecycle
+}
+
+// apiObjects renders the chart and returns the resources it creates whose APIs are deprecated or removed,
+// with the lifecycle of their APIs. The removed APIs are looked up by their kinds, not by the types client-go registers.
+func (c *lintContext) apiObjects() ([]apiObject, error) {
+	r, err := c.rendered()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []apiObject
+	for _, m := range r.manifests {
+		apiVersion, _ := m.object["apiVersion"].(string)
+		gvk := schema.FromAPIVersionAndKind(apiVersion, m.resource.Kind)
+		lifecycle, ok := lifecycleOf(gvk)
+		if !ok {
+			continue
+		}
+		result = append(result, apiObject{
+			file:      m.file,
+			resource:  m.resource,
+			gvk:       gvk,
+			lifecycle: lifecycle,
+		})
+	}
+	return result, nil
+}
+
+// targetKubeMajorMinor returns the major and minor version of the target Kubernetes version.
+func (c *lintContext) targetKubeMajorMinor() (int, int) {
+	major, _ := strconv.Atoi(c.capabilities.KubeVersion.Major)
+	minor, _ := strconv.Atoi(c.capabilities.KubeVersion.Minor)
+	return major, minor
+}
@@ -40,7 +40,7 @@ func Lint(l *action.Lint, path string, vals map[string]interface{}, metadata *ch
 		lowestTolerance = support.WarningSev
 	}
 	result := &action.LintResult{}
-	linter := lintAll(path, vals, l.Namespace, l.Strict, metadata)
+	linter := lintAll(path, vals, l.Namespace, l.KubeVersion, l.Strict, metadata)
 
 	result.Messages = append(result.Messages, linter.Messages...)
 	result.TotalChartsLinted++
@@ -53,8 +53,9 @@ func Lint(l *action.Lint, path string, vals map[string]interface{}, metadata *ch
 	return result
 }
 
-// lintAll runs all of the available linters on the given base directory.
-func lintAll(basedir string, values map[string]interface{}, namespace string, strict bool, metadata *chart.Metadata) support.Linter {
+// lintAll runs all of the available linters on the given base directory,
+// the templates are rendered for kubeVersion if it is set.
+func lintAll(basedir string, values map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, strict bool, metadata *chart.Metadata) support.Linter {
 	// Using abs path to get directory context
 	chartDir, _ := filepath.Abs(basedir)
 
@@ -62,7 +63,7 @@ func lintAll(basedir string, values map[string]interface{}, namespace string, st
 	// For ks-extension it's not exist.
 	lintChartfile(&linter, chartDir, api.DeepCopy(metadata))
 	rules.ValuesWithOverrides(&linter, values)
-	lintTemplates(&linter, values, namespace, strict, api.DeepCopy(metadata))
+	lintTemplates(&linter, values, namespace, kubeVersion, strict, api.DeepCopy(metadata))
 	lintDependencies(&linter, api.DeepCopy(metadata))
 	return linter
 }
@@ -86,7 +87,7 @@ func lintChartfile(linter *support.Linter, chartFilePath string, chartFile *char
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
 }
 
-func lintTemplates(linter *support.Linter, values map[string]interface{}, namespace string, strict bool, metadata *chart.Metadata) {
+func lintTemplates(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, strict bool, metadata *chart.Metadata) {
 	fpath := "templates/"
 	templatesPath := filepath.Join(linter.ChartDir, fpath)
 
@@ -122,7 +123,11 @@ func lintTemplates(linter *support.Linter, values map[string]interface{}, namesp
 	if err != nil {
 		return
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, nil)
+	caps := chartutil.DefaultCapabilities.Copy()
+	if kubeVersion != nil {
+		caps.KubeVersion = *kubeVersion
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, caps)
 	if err != nil {
 		linter.RunLinterRule(support.ErrorSev, fpath, err)
 		return
@@ -192,7 +197,7 @@ func lintTemplates(linter *support.Linter, values map[string]interface{}, namesp
 					// NOTE: set to warnings to allow users to support out-of-date kubernetes
 					// Refs https://github.com/helm/helm/issues/8596
 					linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
-					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct))
+					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct, kubeVersion))
 
 					linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
 					linter.RunLinterRule(support.ErrorSev, fpath, validateListAnnotations(yamlStruct, renderedContent))
@@ -368,7 +373,7 @@ func (e deprecatedAPIError) Error() string {
 	return msg
 }
 
-func validateNoDeprecations(resource *K8sYamlStruct) error {
+func validateNoDeprecations(resource *K8sYamlStruct, kubeVersion *chartutil.KubeVersion) error {
 	// if `resource` does not have an APIVersion or Kind, we cannot test it for deprecation
 	if resource.APIVersion == "" {
 		return nil
@@ -385,11 +390,15 @@ func validateNoDeprecations(resource *K8sYamlStruct) error {
 		}
 		return err
 	}
-	maj, err := strconv.Atoi(k8sVersionMajor)
+	majorVersion, minorVersion := k8sVersionMajor, k8sVersionMinor
+	if kubeVersion != nil {
+		majorVersion, minorVersion = kubeVersion.Major, kubeVersion.Minor
+	}
+	maj, err := strconv.Atoi(majorVersion)
 	if err != nil {
 		return err
 	}
-	min, err := strconv.Atoi(k8sVersionMinor)
+	min, err := strconv.Atoi(minorVersion)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultBaselineFilename is the conventional name of the baseline file in the extension root directory.
+const DefaultBaselineFilename = ".ksbuilder-baseline.json"
+
+// baselineVersion is the version of the baseline file format.
+const baselineVersion = 2
+
+// Baseline is the known findings of an extension, which are not reported again so that lint can be adopted
+// without fixing the legacy findings first.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry is a known finding, identified by its fingerprint. The other fields tell what it is for review.
+type BaselineEntry struct {
+	Fingerprint string    `json:"fingerprint"`
+	RuleID      string    `json:"ruleID"`
+	Severity    Severity  `json:"severity"`
+	File        string    `json:"file,omitempty"`
+	Resource    *Resource `json:"resource,omitempty"`
+}
+
+// digits matches the numbers in messages, such as line numbers, which change when the extension is edited.
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// Fingerprint identifies a finding by its rule, severity, file and resource, which are stable across edits
+// of the extension unlike line numbers. The file is relative to root, the directory of the linted extension,
+// so that the fingerprints don't depend on where lint runs.
+// The findings of helm all have the same rule, and the findings without a resource may have the same file,
+// so these are told apart by their messages with the numbers left out.
+func Fingerprint(f Finding, root string) string {
+	fields := []string{f.RuleID, f.Severity.String(), baselineFile(f, root)}
+	if f.Resource != nil {
+		fields = append(fields, f.Resource.Kind, f.Resource.Namespace, f.Resource.Name)
+	}
+	if f.Source == SourceHelm || f.Resource == nil {
+		fields = append(fields, normalizeMessage(f.Message))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeMessage leaves the numbers and the spacing out of a message.
+func normalizeMessage(message string) string {
+	return strings.Join(strings.Fields(digits.ReplaceAllString(message, "#")), " ")
+}
+
+// baselineFile returns the path of the file of a finding relative to root,
+// the files of helm findings are relative to their subcharts.
+func baselineFile(f Finding, root string) string {
+	if f.Chart == "" || f.File == "" || filepath.IsAbs(f.File) {
+		return filepath.ToSlash(f.File)
+	}
+	rel, err := filepath.Rel(root, filepath.Join(f.Chart, f.File))
+	if err != nil {
+		return filepath.ToSlash(f.File)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// findingRoot returns the root of the extension a finding belongs to, the innermost of roots containing its chart.
+func findingRoot(f Finding, roots []string) string {
+	result := ""
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, f.Chart)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(result) {
+			result = root
+		}
+	}
+	return result
+}
+
+// NewBaseline returns the baseline of the findings of the extensions at roots, sorted by fingerprint.
+func NewBaseline(findings []Finding, roots []string) *Baseline {
+	b := &Baseline{Version: baselineVersion, Findings: []BaselineEntry{}}
+	for _, f := range findings {
+		root := findingRoot(f, roots)
+		fingerprint := Fingerprint(f, root)
+		if b.Has(fingerprint) {
+			continue
+		}
+		b.Findings = append(b.Findings, BaselineEntry{
+			Fingerprint: fingerprint,
+			RuleID:      f.RuleID,
+			Severity:    f.Severity,
+			File:        baselineFile(f, root),
+			Resource:    f.Resource,
+		})
+	}
+	slices.SortFunc(b.Findings, func(x, y BaselineEntry) int {
+		return strings.Compare(x.Fingerprint, y.Fingerprint)
+	})
+	return b
+}
+
+// LoadBaseline reads a baseline file.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s is of version %d, only version %d is supported, write it again by --write-baseline",
+			path, b.Version, baselineVersion)
+	}
+	return b, nil
+}
+
+// Write writes the baseline to path.
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Has reports whether the baseline contains the fingerprint.
+func (b *Baseline) Has(fingerprint string) bool {
+	return slices.ContainsFunc(b.Findings, func(e BaselineEntry) bool {
+		return e.Fingerprint == fingerprint
+	})
+}
@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	deployment := &Resource{Kind: "Deployment", Name: "backend"}
+	tests := []struct {
+		name string
+		a, b Finding
+		root string
+		// same tells whether a and b are expected to have the same fingerprint
+		same bool
+	}{
+		{
+			name: "line numbers of a helm message",
+			a:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "unable to parse YAML: line 12: mapping values are not allowed"},
+			b:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "unable to parse YAML: line 40: mapping values are not allowed"},
+			root: "ext",
+			same: true,
+		},
+		{
+			name: "a helm error in the file of a helm warning",
+			a:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityWarning, Chart: "ext", File: "templates/a.yaml", Message: "object name does not conform to Kubernetes naming requirements"},
+			b:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "unable to parse YAML"},
+			root: "ext",
+		},
+		{
+			name: "the same helm message in another severity",
+			a:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityInfo, Chart: "ext", File: "templates/a.yaml", Message: "icon is recommended"},
+			b:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "icon is recommended"},
+			root: "ext",
+		},
+		{
+			name: "chart level helm findings",
+			a:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityInfo, Chart: "ext", Message: "icon is recommended"},
+			b:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityInfo, Chart: "ext", Message: "chart directory is missing"},
+			root: "ext",
+		},
+		{
+			name: "findings without resource in the same file",
+			a:    Finding{RuleID: "locales", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "extension.yaml", Message: `locale "zh" is incomplete: missing README_zh.md`},
+			b:    Finding{RuleID: "locales", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "extension.yaml", Message: `locale "en" is incomplete: missing CHANGELOG.md`},
+			root: "ext",
+		},
+		{
+			name: "messages of a resource",
+			a:    Finding{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "charts/backend/templates/deployment.yaml", Resource: deployment, Message: "image nginx:1.25 is not declared"},
+			b:    Finding{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "charts/backend/templates/deployment.yaml", Resource: deployment, Message: "image nginx:1.27 is not declared"},
+			root: "ext",
+			same: true,
+		},
+		{
+			name: "resources of the same kind and name in different namespaces",
+			a:    Finding{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "templates/a.yaml", Resource: &Resource{Kind: "Deployment", Namespace: "a", Name: "backend"}},
+			b:    Finding{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "templates/a.yaml", Resource: &Resource{Kind: "Deployment", Namespace: "b", Name: "backend"}},
+			root: "ext",
+		},
+		{
+			name: "the files of subcharts are relative to the extension",
+			a:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext/charts/backend", File: "templates/a.yaml", Message: "unable to parse YAML"},
+			b:    Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext/charts/frontend", File: "templates/a.yaml", Message: "unable to parse YAML"},
+			root: "ext",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Fingerprint(tt.a, tt.root), Fingerprint(tt.b, tt.root)
+			if same := a == b; same != tt.same {
+				t.Errorf("same fingerprint = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintIndependentOfRoot(t *testing.T) {
+	f := Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, File: "templates/a.yaml", Message: "unable to parse YAML"}
+	relative, absolute := f, f
+	relative.Chart = filepath.Join("ext", "charts", "backend")
+	absolute.Chart = filepath.Join("/work", "ext", "charts", "backend")
+	if Fingerprint(relative, "ext") != Fingerprint(absolute, filepath.Join("/work", "ext")) {
+		t.Error("the fingerprints differ by where lint runs")
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	warning := Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityWarning, Chart: "ext", File: "templates/a.yaml", Message: "object name does not conform to Kubernetes naming requirements"}
+	baseline := NewBaseline([]Finding{warning}, []string{"ext"})
+
+	newError := Finding{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "unable to parse YAML: line 3: did not find expected key"}
+	result := &Result{Findings: []Finding{warning, newError}}
+	result.ApplyBaseline(baseline, []string{"ext"})
+
+	if len(result.Baselined) != 1 || result.Baselined[0].Message != warning.Message {
+		t.Errorf("baselined = %v, want the old warning", result.Baselined)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Message != newError.Message {
+		t.Errorf("findings = %v, want the new error", result.Findings)
+	}
+	if err := result.Err(); err == nil {
+		t.Error("the new error doesn't fail the lint run")
+	}
+}
@@ -0,0 +1,68 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ValuesMatrix is the values the builtin rules render the chart with, every combination of them is linted.
+//
+// An example of the --values-matrix file, which is linted in 4 combinations:
+//
+//	matrix:
+//	  frontend.enabled: [true, false]
+//	  tags.agent: [true, false]
+type ValuesMatrix struct {
+	// Matrix is the candidate values keyed by the dot separated path of the value, as the keys of --set.
+	Matrix map[string][]any `json:"matrix"`
+}
+
+// Combination is a set of values from a ValuesMatrix, in the form of --set-json arguments sorted by key.
+type Combination []string
+
+func (c Combination) String() string {
+	return strings.Join(c, ", ")
+}
+
+// LoadValuesMatrix reads a values matrix file.
+func LoadValuesMatrix(path string) (*ValuesMatrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	matrix := &ValuesMatrix{}
+	if err := yaml.UnmarshalStrict(data, matrix); err != nil {
+		return nil, fmt.Errorf("failed to parse values matrix %s: %w", path, err)
+	}
+	for _, key := range slices.Sorted(maps.Keys(matrix.Matrix)) {
+		if len(matrix.Matrix[key]) == 0 {
+			return nil, fmt.Errorf("values matrix %s has no values for %s", path, key)
+		}
+	}
+	return matrix, nil
+}
+
+// Combinations returns all combinations of the values in the matrix.
+func (m *ValuesMatrix) Combinations() ([]Combination, error) {
+	combinations := []Combination{nil}
+	for _, key := range slices.Sorted(maps.Keys(m.Matrix)) {
+		var next []Combination
+		for _, c := range combinations {
+			for _, v := range m.Matrix[key] {
+				value, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value of %s in values matrix: %w", key, err)
+				}
+				next = append(next, append(slices.Clone(c), fmt.Sprintf("%s=%s", key, value)))
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
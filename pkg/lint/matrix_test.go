@@ -0,0 +1,84 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValuesMatrixCombinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix string
+		want   []Combination
+		// wantErr tells whether loading the matrix is expected to fail
+		wantErr bool
+	}{
+		{
+			name:   "no values",
+			matrix: "matrix: {}\n",
+			want:   []Combination{nil},
+		},
+		{
+			name:   "one value",
+			matrix: "matrix:\n  frontend.enabled: [true, false]\n",
+			want: []Combination{
+				{"frontend.enabled=true"},
+				{"frontend.enabled=false"},
+			},
+		},
+		{
+			name:   "values sorted by key",
+			matrix: "matrix:\n  tags.agent: [true, false]\n  frontend.enabled: [true, false]\n",
+			want: []Combination{
+				{"frontend.enabled=true", "tags.agent=true"},
+				{"frontend.enabled=true", "tags.agent=false"},
+				{"frontend.enabled=false", "tags.agent=true"},
+				{"frontend.enabled=false", "tags.agent=false"},
+			},
+		},
+		{
+			name:   "values of any type as JSON",
+			matrix: "matrix:\n  replicas: [1, \"2\"]\n  global.nodeSelector: [{role: edge}, null]\n",
+			want: []Combination{
+				{`global.nodeSelector={"role":"edge"}`, "replicas=1"},
+				{`global.nodeSelector={"role":"edge"}`, `replicas="2"`},
+				{"global.nodeSelector=null", "replicas=1"},
+				{"global.nodeSelector=null", `replicas="2"`},
+			},
+		},
+		{
+			name:    "a key without values",
+			matrix:  "matrix:\n  frontend.enabled: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "an unknown field",
+			matrix:  "values:\n  frontend.enabled: [true]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "matrix.yaml")
+			if err := os.WriteFile(path, []byte(tt.matrix), 0644); err != nil {
+				t.Fatal(err)
+			}
+			matrix, err := LoadValuesMatrix(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadValuesMatrix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := matrix.Combinations()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("Combinations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
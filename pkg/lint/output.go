@@ -0,0 +1,362 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputSARIF = "sarif"
+	OutputJUnit = "junit"
+)
+
+// OutputFormats lists the supported values of the --output flag.
+var OutputFormats = []string{OutputText, OutputJSON, OutputSARIF, OutputJUnit}
+
+// ValidateOutputFormat checks that format is one of OutputFormats.
+func ValidateOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, must be one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// Print writes the result to w in the given format.
+func Print(w io.Writer, format string, result *Result) error {
+	switch format {
+	case OutputText:
+		return printText(w, result)
+	case OutputJSON:
+		return printJSON(w, result)
+	case OutputSARIF:
+		return printSARIF(w, result)
+	case OutputJUnit:
+		return printJUnit(w, result)
+	}
+	return ValidateOutputFormat(format)
+}
+
+// groupByChart groups the findings of one source by chart, keeping the order in which charts first appear.
+func groupByChart(findings []Finding, source string) ([]string, map[string][]Finding) {
+	var charts []string
+	groups := make(map[string][]Finding)
+	for _, f := range findings {
+		if f.Source != source {
+			continue
+		}
+		if _, ok := groups[f.Chart]; !ok {
+			charts = append(charts, f.Chart)
+		}
+		groups[f.Chart] = append(groups[f.Chart], f)
+	}
+	return charts, groups
+}
+
+func printText(w io.Writer, result *Result) error {
+	var b strings.Builder
+	for _, source := range []string{SourceHelm, SourceKubeSphere} {
+		fmt.Fprintf(&b, "\n#################### lint by %s ####################\n", source)
+		charts, groups := groupByChart(result.Findings, source)
+		for _, chart := range charts {
+			fmt.Fprintf(&b, "==> Linting %s\n", chart)
+			for _, f := range groups[chart] {
+				writeTextFinding(&b, f)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(result.Suppressed) > 0 {
+		b.WriteString("\n#################### suppressed ####################\n")
+		for _, source := range []string{SourceHelm, SourceKubeSphere} {
+			charts, groups := groupByChart(result.Suppressed, source)
+			for _, chart := range charts {
+				fmt.Fprintf(&b, "==> Linting %s by %s\n", chart, source)
+				for _, f := range groups[chart] {
+					writeTextFinding(&b, f)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	fmt.Fprintln(&b, result.Summary())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTextFinding writes a finding as a line of the text output.
+func writeTextFinding(b *strings.Builder, f Finding) {
+	fmt.Fprintf(b, "[%s] ", strings.ToUpper(f.Severity.String()))
+	if f.File != "" {
+		fmt.Fprintf(b, "%s: ", f.File)
+	}
+	if f.Resource != nil {
+		fmt.Fprintf(b, "%s: ", f.Resource)
+	}
+	b.WriteString(f.Message)
+	if len(f.Combinations) > 0 {
+		fmt.Fprintf(b, " [values: %s]", strings.Join(f.Combinations, " | "))
+	}
+	if f.Source != SourceHelm {
+		fmt.Fprintf(b, " (%s)", f.RuleID)
+	}
+	if f.Suppression != "" {
+		fmt.Fprintf(b, " suppressed by %s", f.Suppression)
+	}
+	b.WriteString("\n")
+}
+
+func printJSON(w io.Writer, result *Result) error {
+	report := struct {
+		*Result
+		Summary Summary `json:"summary"`
+	}{
+		Result:  result,
+		Summary: result.Summary(),
+	}
+	if report.Charts == nil {
+		report.Charts = []string{}
+	}
+	if report.Findings == nil {
+		report.Findings = []Finding{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// SARIF 2.1.0, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func printSARIF(w io.Writer, result *Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ksbuilder",
+			InformationURI: "https://github.com/kubesphere/ksbuilder",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := make(map[string]bool)
+	for _, r := range result.Rules {
+		rules[r.ID] = true
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     &sarifMessage{Text: r.Description},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+	for _, f := range append(slices.Clone(result.Findings), result.Suppressed...) {
+		if !rules[f.RuleID] {
+			rules[f.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: f.RuleID})
+		}
+		r := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: findingMessage(f)},
+		}
+		if uri := findingPath(f); uri != "" {
+			r.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+			}}}
+		}
+		if f.Suppression != "" {
+			r.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: f.Suppression}}
+		}
+		run.Results = append(run.Results, r)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func printJUnit(w io.Writer, result *Result) error {
+	suites := junitTestSuites{TestSuites: []junitTestSuite{}}
+	for _, source := range []string{SourceHelm, SourceKubeSphere} {
+		charts, groups := groupByChart(result.Findings, source)
+		for _, chart := range charts {
+			suite := junitTestSuite{Name: fmt.Sprintf("%s: %s", source, chart)}
+			for _, f := range groups[chart] {
+				tc := junitTestCase{
+					Name:      fmt.Sprintf("%s %s", f.RuleID, findingPath(f)),
+					ClassName: chart,
+				}
+				if f.Severity == SeverityInfo {
+					tc.SystemOut = findingMessage(f)
+				} else {
+					tc.Failure = &junitFailure{
+						Message: f.Message,
+						Type:    f.Severity.String(),
+						Text:    findingMessage(f),
+					}
+					suite.Failures++
+				}
+				suite.Tests++
+				suite.TestCases = append(suite.TestCases, tc)
+			}
+			suites.TestSuites = append(suites.TestSuites, suite)
+		}
+	}
+	if len(result.Suppressed) > 0 {
+		// suppressed findings are skipped test cases, so that CI reports still show them
+		suite := junitTestSuite{Name: "suppressed"}
+		for _, f := range result.Suppressed {
+			suite.Tests++
+			suite.Skipped++
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      fmt.Sprintf("%s %s", f.RuleID, findingPath(f)),
+				ClassName: f.Chart,
+				Skipped:   &junitSkipped{Message: fmt.Sprintf("%s, suppressed by %s", findingMessage(f), f.Suppression)},
+			})
+		}
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// findingPath returns the path of the file a finding refers to, relative to the linted directory.
+func findingPath(f Finding) string {
+	switch {
+	case f.Chart == "" || filepath.IsAbs(f.File):
+		return filepath.ToSlash(f.File)
+	case f.File == "":
+		return filepath.ToSlash(f.Chart)
+	}
+	return filepath.ToSlash(filepath.Join(f.Chart, f.File))
+}
+
+// findingMessage returns the message of a finding prefixed with the resource it refers to,
+// and followed by the combinations of values that trigger it.
+func findingMessage(f Finding) string {
+	message := f.Message
+	if f.Resource != nil {
+		message = fmt.Sprintf("%s: %s", f.Resource, message)
+	}
+	if len(f.Combinations) > 0 {
+		message = fmt.Sprintf("%s [values: %s]", message, strings.Join(f.Combinations, " | "))
+	}
+	return message
+}
@@ -0,0 +1,199 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// testResult is a lint run with findings of both sources, of all severities, and a suppressed one.
+func testResult() *Result {
+	deployment := &Resource{Kind: "Deployment", Name: "backend"}
+	return &Result{
+		Charts: []string{"ext"},
+		Rules: []Rule{
+			{ID: "images", Description: "images are declared", Severity: SeverityWarning},
+			{ID: "global-tolerations", Description: "global.tolerations are propagated", Severity: SeverityError},
+		},
+		Findings: []Finding{
+			{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityInfo, Chart: "ext", File: "Chart.yaml", Message: "icon is recommended"},
+			{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "ext", File: "templates/a.yaml", Message: "unable to parse YAML"},
+			{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "ext", File: "templates/deployment.yaml", Resource: deployment, Message: "image nginx is not declared", Combinations: []string{"backend.enabled=true"}},
+		},
+		Suppressed: []Finding{
+			{RuleID: "global-tolerations", Source: SourceKubeSphere, Severity: SeverityError, Chart: "ext", File: "templates/deployment.yaml", Resource: deployment, Message: "global.tolerations doesn't work", Suppression: "an ignore comment of Deployment/backend"},
+		},
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: OutputText},
+		{format: OutputJSON},
+		{format: OutputSARIF},
+		{format: OutputJUnit},
+		{format: "yaml", wantErr: true},
+		{format: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if err := ValidateOutputFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOutputFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if err := Print(&bytes.Buffer{}, tt.format, &Result{}); (err != nil) != tt.wantErr {
+				t.Errorf("Print(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *Result
+		// want are the fields of the report as JSON
+		want map[string]string
+	}{
+		{
+			name:   "no findings",
+			result: &Result{},
+			want: map[string]string{
+				"strict":   `false`,
+				"charts":   `[]`,
+				"findings": `[]`,
+				"summary":  `{"charts":0,"failed":0,"errors":0,"warnings":0,"infos":0,"suppressed":0,"baselined":0}`,
+			},
+		},
+		{
+			name:   "findings",
+			result: testResult(),
+			want: map[string]string{
+				"charts":  `["ext"]`,
+				"rules":   `[{"id":"images","description":"images are declared","severity":"warning"},{"id":"global-tolerations","description":"global.tolerations are propagated","severity":"error"}]`,
+				"summary": `{"charts":1,"failed":1,"errors":1,"warnings":1,"infos":1,"suppressed":1,"baselined":0}`,
+				"findings": `[{"ruleID":"helm","severity":"info","source":"helm","chart":"ext","file":"Chart.yaml","message":"icon is recommended"},` +
+					`{"ruleID":"helm","severity":"error","source":"helm","chart":"ext","file":"templates/a.yaml","message":"unable to parse YAML"},` +
+					`{"ruleID":"images","severity":"warning","source":"kubesphere","chart":"ext","file":"templates/deployment.yaml","resource":{"kind":"Deployment","name":"backend"},"message":"image nginx is not declared","combinations":["backend.enabled=true"]}]`,
+				"suppressed": `[{"ruleID":"global-tolerations","severity":"error","source":"kubesphere","chart":"ext","file":"templates/deployment.yaml","resource":{"kind":"Deployment","name":"backend"},"message":"global.tolerations doesn't work","suppression":"an ignore comment of Deployment/backend"}]`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Print(&b, OutputJSON, tt.result); err != nil {
+				t.Fatal(err)
+			}
+			var report map[string]json.RawMessage
+			if err := json.Unmarshal(b.Bytes(), &report); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			for key, want := range tt.want {
+				var got bytes.Buffer
+				if err := json.Compact(&got, report[key]); err != nil {
+					t.Fatalf("invalid %s: %v", key, err)
+				}
+				if got.String() != want {
+					t.Errorf("%s = %s, want %s", key, got.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintSARIF(t *testing.T) {
+	var b bytes.Buffer
+	if err := Print(&b, OutputSARIF, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %s with %d run(s), want 2.1.0 with 1 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	var ruleIDs []string
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+	}
+	// the rules of the run come first, then those only found in the findings
+	if got, want := strings.Join(ruleIDs, ","), "images,global-tolerations,helm"; got != want {
+		t.Errorf("rules = %s, want %s", got, want)
+	}
+	if level := run.Tool.Driver.Rules[1].DefaultConfiguration.Level; level != "error" {
+		t.Errorf("default level of global-tolerations = %s, want error", level)
+	}
+
+	tests := []struct {
+		ruleID, level, message, uri string
+		suppressed                  bool
+	}{
+		{ruleID: SourceHelm, level: "note", message: "icon is recommended", uri: "ext/Chart.yaml"},
+		{ruleID: SourceHelm, level: "error", message: "unable to parse YAML", uri: "ext/templates/a.yaml"},
+		{ruleID: "images", level: "warning", message: "Deployment/backend: image nginx is not declared [values: backend.enabled=true]", uri: "ext/templates/deployment.yaml"},
+		{ruleID: "global-tolerations", level: "error", message: "Deployment/backend: global.tolerations doesn't work", uri: "ext/templates/deployment.yaml", suppressed: true},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("%d result(s), want %d", len(run.Results), len(tests))
+	}
+	for i, tt := range tests {
+		r := run.Results[i]
+		if r.RuleID != tt.ruleID || r.Level != tt.level || r.Message.Text != tt.message {
+			t.Errorf("result %d = %s %s %q, want %s %s %q", i, r.RuleID, r.Level, r.Message.Text, tt.ruleID, tt.level, tt.message)
+		}
+		if len(r.Locations) != 1 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != tt.uri {
+			t.Errorf("result %d locations = %v, want %s", i, r.Locations, tt.uri)
+		}
+		if suppressed := len(r.Suppressions) == 1 && r.Suppressions[0].Kind == "inSource"; suppressed != tt.suppressed {
+			t.Errorf("result %d suppressions = %v, want suppressed %v", i, r.Suppressions, tt.suppressed)
+		}
+	}
+}
+
+func TestPrintJUnit(t *testing.T) {
+	var b bytes.Buffer
+	if err := Print(&b, OutputJUnit, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+
+	tests := []struct {
+		name                     string
+		tests, failures, skipped int
+	}{
+		{name: "helm: ext", tests: 2, failures: 1},
+		{name: "kubesphere: ext", tests: 1, failures: 1},
+		{name: "suppressed", tests: 1, skipped: 1},
+	}
+	if len(suites.TestSuites) != len(tests) {
+		t.Fatalf("%d test suite(s), want %d", len(suites.TestSuites), len(tests))
+	}
+	for i, tt := range tests {
+		s := suites.TestSuites[i]
+		if s.Name != tt.name || s.Tests != tt.tests || s.Failures != tt.failures || s.Skipped != tt.skipped {
+			t.Errorf("test suite %d = %s with %d test(s), %d failure(s), %d skipped, want %s with %d, %d, %d",
+				i, s.Name, s.Tests, s.Failures, s.Skipped, tt.name, tt.tests, tt.failures, tt.skipped)
+		}
+	}
+
+	// info findings pass with their messages in the output
+	info := suites.TestSuites[0].TestCases[0]
+	if info.Failure != nil || info.SystemOut != "icon is recommended" {
+		t.Errorf("info test case = %+v, want a passed one with the message", info)
+	}
+	failure := suites.TestSuites[1].TestCases[0]
+	if failure.Name != "images ext/templates/deployment.yaml" || failure.Failure == nil || failure.Failure.Type != "warning" {
+		t.Errorf("warning test case = %+v, want a failure of images", failure)
+	}
+}
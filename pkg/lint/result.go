@@ -0,0 +1,224 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// SourceHelm marks findings reported by the helm chart linter.
+	SourceHelm = "helm"
+	// SourceKubeSphere marks findings reported by the builtin KubeSphere rules.
+	SourceKubeSphere = "kubesphere"
+)
+
+// Severity is the level of a lint finding.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < SeverityInfo || s > SeverityError {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity converts a severity name such as "warning" to a Severity.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(n, name) {
+			return Severity(i), nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q, must be one of %s", name, strings.Join(severityNames, ", "))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(data []byte) error {
+	severity, err := ParseSeverity(string(data))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// Resource identifies a rendered kubernetes resource.
+type Resource struct {
+	Kind string `json:"kind"`
+	// Namespace is the namespace the resource is rendered with, empty for the release namespace
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (r *Resource) String() string {
+	if r.Namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", r.Kind, r.Namespace, r.Name)
+	}
+	return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+}
+
+// Finding is a single problem reported by lint.
+type Finding struct {
+	RuleID   string    `json:"ruleID"`
+	Severity Severity  `json:"severity"`
+	Source   string    `json:"source"`
+	Chart    string    `json:"chart,omitempty"`
+	File     string    `json:"file,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
+	Message  string    `json:"message"`
+	// Combinations are the combinations of the values matrix that trigger the finding,
+	// it is empty when the finding is triggered by all of them.
+	Combinations []string `json:"combinations,omitempty"`
+	// Suppression tells how a suppressed finding is ignored, such as by an annotation of its resource.
+	Suppression string `json:"suppression,omitempty"`
+	// KeepSeverity keeps the severity a rule reports the finding at, rather than the severity the rule is configured
+	// with, when it is lower. It is for the findings that are not the problems of the extension itself.
+	KeepSeverity bool `json:"-"`
+}
+
+// Result collects the findings of a lint run.
+type Result struct {
+	// Strict makes warnings fail the lint run as well as errors.
+	Strict bool     `json:"strict"`
+	Charts []string `json:"charts"`
+	// Rules are the builtin rules enabled in the lint run, with their effective severity.
+	Rules    []Rule    `json:"rules,omitempty"`
+	Findings []Finding `json:"findings"`
+	// Suppressed are the findings ignored by the extension, they don't fail the lint run
+	// but are listed so that reviewers can audit them.
+	Suppressed []Finding `json:"suppressed,omitempty"`
+	// Baselined are the known findings of the baseline, they are neither reported nor fail the lint run.
+	Baselined []Finding `json:"baselined,omitempty"`
+}
+
+// Summary counts the charts and findings of a lint run.
+type Summary struct {
+	Charts   int `json:"charts"`
+	Failed   int `json:"failed"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+	// Suppressed counts the suppressed findings of all severities.
+	Suppressed int `json:"suppressed"`
+	// Baselined counts the findings of the baseline.
+	Baselined int `json:"baselined"`
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d chart(s) linted, %d chart(s) failed: %d error(s), %d warning(s), %d info(s), %d suppressed, %d baselined",
+		s.Charts, s.Failed, s.Errors, s.Warnings, s.Infos, s.Suppressed, s.Baselined)
+}
+
+// AddChart records that the chart at path has been linted.
+func (r *Result) AddChart(path string) {
+	if !slices.Contains(r.Charts, path) {
+		r.Charts = append(r.Charts, path)
+	}
+}
+
+// Add appends findings to the result.
+func (r *Result) Add(findings ...Finding) {
+	for _, f := range findings {
+		if f.Chart != "" {
+			r.AddChart(f.Chart)
+		}
+	}
+	r.Findings = append(r.Findings, findings...)
+}
+
+// Suppress moves the ignored findings to the suppressed findings,
+// suppression returns how a finding is ignored, or an empty string when it isn't.
+func (r *Result) Suppress(suppression func(f Finding) string) {
+	findings := r.Findings[:0]
+	for _, f := range r.Findings {
+		if f.Suppression = suppression(f); f.Suppression != "" {
+			r.Suppressed = append(r.Suppressed, f)
+		} else {
+			findings = append(findings, f)
+		}
+	}
+	r.Findings = findings
+}
+
+// ApplyBaseline moves the findings of the baseline to the baselined findings, so that only new findings are reported.
+// roots are the paths of the linted extensions the fingerprints are relative to.
+func (r *Result) ApplyBaseline(b *Baseline, roots []string) {
+	findings := r.Findings[:0]
+	for _, f := range r.Findings {
+		if b.Has(Fingerprint(f, findingRoot(f, roots))) {
+			r.Baselined = append(r.Baselined, f)
+		} else {
+			findings = append(findings, f)
+		}
+	}
+	r.Findings = findings
+}
+
+// DropInfos removes the findings of info severity of all sources, including the suppressed and baselined ones,
+// so that only warnings and errors are reported and counted.
+func (r *Result) DropInfos() {
+	isInfo := func(f Finding) bool { return f.Severity == SeverityInfo }
+	r.Findings = slices.DeleteFunc(r.Findings, isInfo)
+	r.Suppressed = slices.DeleteFunc(r.Suppressed, isInfo)
+	r.Baselined = slices.DeleteFunc(r.Baselined, isInfo)
+}
+
+// Count returns the number of findings with the given severity.
+func (r *Result) Count(severity Severity) int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// IsFailure reports whether the finding fails the lint run.
+func (r *Result) IsFailure(f Finding) bool {
+	if r.Strict {
+		return f.Severity >= SeverityWarning
+	}
+	return f.Severity >= SeverityError
+}
+
+// Summary returns the counts of the lint run, combining the findings of all sources.
+func (r *Result) Summary() Summary {
+	failed := sets.New[string]()
+	for _, f := range r.Findings {
+		if r.IsFailure(f) {
+			failed.Insert(f.Chart)
+		}
+	}
+	return Summary{
+		Charts:     len(r.Charts),
+		Failed:     failed.Len(),
+		Errors:     r.Count(SeverityError),
+		Warnings:   r.Count(SeverityWarning),
+		Infos:      r.Count(SeverityInfo),
+		Suppressed: len(r.Suppressed),
+		Baselined:  len(r.Baselined),
+	}
+}
+
+// Err returns an error when any chart failed the lint run.
+func (r *Result) Err() error {
+	if summary := r.Summary(); summary.Failed > 0 {
+		return fmt.Errorf("lint failed: %s", summary)
+	}
+	return nil
+}
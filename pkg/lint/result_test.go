@@ -0,0 +1,65 @@
+package lint
+
+import "testing"
+
+func TestSummary(t *testing.T) {
+	findings := []Finding{
+		{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityInfo, Chart: "a", Message: "icon is recommended"},
+		{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "a", Message: "image nginx is not declared"},
+		{RuleID: SourceHelm, Source: SourceHelm, Severity: SeverityError, Chart: "b", Message: "unable to parse YAML"},
+		{RuleID: "global-tolerations", Source: SourceKubeSphere, Severity: SeverityError, Chart: "b", Message: "global.tolerations doesn't work"},
+		{RuleID: "images", Source: SourceKubeSphere, Severity: SeverityWarning, Chart: "c", Message: "image redis is not declared"},
+	}
+	suppressed := []Finding{
+		{RuleID: "global-affinity", Source: SourceKubeSphere, Severity: SeverityError, Chart: "c", Message: "global.affinity doesn't work"},
+	}
+
+	tests := []struct {
+		name       string
+		strict     bool
+		findings   []Finding
+		suppressed []Finding
+		want       Summary
+	}{
+		{
+			name: "no findings",
+			want: Summary{},
+		},
+		{
+			name:       "errors fail",
+			findings:   findings,
+			suppressed: suppressed,
+			want:       Summary{Charts: 3, Failed: 1, Errors: 2, Warnings: 2, Infos: 1, Suppressed: 1},
+		},
+		{
+			name:       "warnings fail in strict mode",
+			strict:     true,
+			findings:   findings,
+			suppressed: suppressed,
+			want:       Summary{Charts: 3, Failed: 3, Errors: 2, Warnings: 2, Infos: 1, Suppressed: 1},
+		},
+		{
+			name:     "infos never fail",
+			strict:   true,
+			findings: findings[:1],
+			want:     Summary{Charts: 1, Infos: 1},
+		},
+		{
+			name:       "suppressed errors don't fail",
+			suppressed: suppressed,
+			want:       Summary{Suppressed: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Strict: tt.strict, Suppressed: tt.suppressed}
+			result.Add(tt.findings...)
+			if got := result.Summary(); got != tt.want {
+				t.Errorf("Summary() = %+v, want %+v", got, tt.want)
+			}
+			if err := result.Err(); (err != nil) != (tt.want.Failed > 0) {
+				t.Errorf("Err() = %v, want failure %v", err, tt.want.Failed > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFilename is the name of the lint configuration file in the extension root directory.
+const ConfigFilename = ".ksbuilder-lint.yaml"
+
+// PackSecurity is the rule pack checking the security posture of the rendered workloads.
+const PackSecurity = "security"
+
+// Rule describes a builtin lint rule.
+type Rule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	// OptIn rules only run when they are enabled explicitly.
+	OptIn bool `json:"optIn,omitempty"`
+	// Pack is the rule pack the rule belongs to, the rules of a pack are enabled together.
+	Pack string `json:"pack,omitempty"`
+}
+
+// PackRules returns the IDs of the rules in the pack.
+func PackRules(rules []Rule, pack string) []string {
+	var ids []string
+	for _, r := range rules {
+		if r.Pack == pack {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// Config turns rules on or off and overrides their severity.
+//
+// An example of .ksbuilder-lint.yaml:
+//
+//	disable:
+//	  - images
+//	severity:
+//	  global-node-selector: error
+//	podTemplates:
+//	  Rollout:
+//	    - spec.template
+type Config struct {
+	Enable   []string            `json:"enable,omitempty"`
+	Disable  []string            `json:"disable,omitempty"`
+	Severity map[string]Severity `json:"severity,omitempty"`
+	// PodTemplates are the dot separated paths of pod templates in custom kinds, keyed by kind,
+	// so that the rules inspecting workloads also inspect the pods of these kinds.
+	PodTemplates map[string][]string `json:"podTemplates,omitempty"`
+}
+
+// LoadConfig reads the lint configuration file in dir, an empty configuration is returned if it does not exist.
+func LoadConfig(dir string) (*Config, error) {
+	// the extension may be a packaged file
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return &Config{}, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigFilename, err)
+	}
+	return config, nil
+}
+
+// ResolveRules applies the configs in order to the rules, so that a later config overrides an earlier one,
+// and returns the enabled rules with their effective severity.
+func ResolveRules(rules []Rule, configs ...*Config) ([]Rule, error) {
+	enabled := make(map[string]bool, len(rules))
+	severity := make(map[string]Severity, len(rules))
+	for _, r := range rules {
+		enabled[r.ID] = !r.OptIn
+		severity[r.ID] = r.Severity
+	}
+
+	var unknown []string
+	check := func(id string) bool {
+		if _, ok := enabled[id]; !ok {
+			unknown = append(unknown, id)
+			return false
+		}
+		return true
+	}
+	for _, c := range configs {
+		if c == nil {
+			continue
+		}
+		for _, id := range c.Enable {
+			if check(id) {
+				enabled[id] = true
+			}
+		}
+		for _, id := range c.Disable {
+			if check(id) {
+				enabled[id] = false
+			}
+		}
+		for id, s := range c.Severity {
+			if check(id) {
+				severity[id] = s
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("unknown lint rule(s): %s", strings.Join(slices.Compact(unknown), ", "))
+	}
+
+	var result []Rule
+	for _, r := range rules {
+		if enabled[r.ID] {
+			r.Severity = severity[r.ID]
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveRules(t *testing.T) {
+	rules := []Rule{
+		{ID: "images", Severity: SeverityWarning},
+		{ID: "global-tolerations", Severity: SeverityError},
+		{ID: "security-run-as-root", Severity: SeverityWarning, OptIn: true, Pack: PackSecurity},
+		{ID: "security-probes", Severity: SeverityWarning, OptIn: true, Pack: PackSecurity},
+	}
+	pack := &Config{Enable: PackRules(rules, PackSecurity)}
+
+	tests := []struct {
+		name    string
+		configs []*Config
+		// want are the enabled rules with their severities, e.g. images=warning
+		want    string
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: "images=warning,global-tolerations=error",
+		},
+		{
+			name:    "the rules of a pack",
+			configs: []*Config{pack, nil, nil},
+			want:    "images=warning,global-tolerations=error,security-run-as-root=warning,security-probes=warning",
+		},
+		{
+			name:    "the config file disables a rule of the pack",
+			configs: []*Config{pack, {Disable: []string{"security-probes"}}, nil},
+			want:    "images=warning,global-tolerations=error,security-run-as-root=warning",
+		},
+		{
+			name:    "the flags enable a rule the config file disables",
+			configs: []*Config{pack, {Disable: []string{"security-probes", "images"}}, {Enable: []string{"security-probes"}}},
+			want:    "global-tolerations=error,security-run-as-root=warning,security-probes=warning",
+		},
+		{
+			name:    "the flags disable a rule of the pack",
+			configs: []*Config{pack, nil, {Disable: []string{"security-run-as-root"}}},
+			want:    "images=warning,global-tolerations=error,security-probes=warning",
+		},
+		{
+			name:    "an opt-in rule without its pack",
+			configs: []*Config{nil, {Enable: []string{"security-probes"}}, nil},
+			want:    "images=warning,global-tolerations=error,security-probes=warning",
+		},
+		{
+			name:    "severities are overridden",
+			configs: []*Config{nil, {Severity: map[string]Severity{"images": SeverityError, "global-tolerations": SeverityInfo}}, nil},
+			want:    "images=error,global-tolerations=info",
+		},
+		{
+			name:    "a disabled rule stays disabled with a severity",
+			configs: []*Config{nil, {Disable: []string{"images"}, Severity: map[string]Severity{"images": SeverityError}}, nil},
+			want:    "global-tolerations=error",
+		},
+		{
+			name: "unknown rules of all configs",
+			configs: []*Config{nil,
+				{Disable: []string{"image"}, Severity: map[string]Severity{"tolerations": SeverityError}},
+				{Enable: []string{"image"}},
+			},
+			wantErr: "unknown lint rule(s): image, tolerations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := ResolveRules(rules, tt.configs...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ResolveRules() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range resolved {
+				got = append(got, r.ID+"="+r.Severity.String())
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("ResolveRules() = %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"path"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -43,19 +42,11 @@ func ParseExtension(name string, zipFile []byte) (*Extension, error) {
 
 	readmeData := corev1alpha1.Locales{}
 	for _, lang := range supportedLanguages {
-		readmeFileName := "README.md"
-		if lang != corev1alpha1.LanguageCodeEn {
-			readmeFileName = fmt.Sprintf("README_%s.md", lang)
-		}
-		readmeData[lang] = corev1alpha1.LocaleString(data[path.Join(name, readmeFileName)])
+		readmeData[lang] = corev1alpha1.LocaleString(data[path.Join(name, api.ReadmeFilename(lang))])
 	}
 	changelogData := corev1alpha1.Locales{}
 	for _, lang := range supportedLanguages {
-		changelogFileName := "CHANGELOG.md"
-		if lang != corev1alpha1.LanguageCodeEn {
-			changelogFileName = fmt.Sprintf("CHANGELOG_%s.md", lang)
-		}
-		changelogData[lang] = corev1alpha1.LocaleString(data[path.Join(name, changelogFileName)])
+		changelogData[lang] = corev1alpha1.LocaleString(data[path.Join(name, api.ChangelogFilename(lang))])
 	}
 
 	return &Extension{
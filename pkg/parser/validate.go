@@ -40,11 +40,7 @@ func ValidateExtension(name string, zipFile []byte) error {
 		if _, err = io.ReadFull(tr, buffer); err != nil && err != io.EOF {
 			return fmt.Errorf("read tar file failed: %s", err.Error())
 		}
-		metadata, err := api.ParseMetadata(buffer)
-		if err != nil {
-			return fmt.Errorf("parse the extension metadata failed: %s", err.Error())
-		}
-		if err = metadata.Validate(); err != nil {
+		if _, err = api.ParseMetadata(buffer); err != nil {
 			return fmt.Errorf("validate the extension metadata failed: %s", err.Error())
 		}
 		return nil